@@ -1,10 +1,18 @@
 package envio
 
-import "reflect"
+import (
+	"encoding"
+	"reflect"
+)
 
 var (
 	getter = reflect.TypeOf((*Getter)(nil)).Elem()
 	setter = reflect.TypeOf((*Setter)(nil)).Elem()
+
+	textUnmarshaler   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshaler     = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	binaryUnmarshaler = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	binaryMarshaler   = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
 )
 
 // Getter is the interface implemented by types that can themselves get ENVs.
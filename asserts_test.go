@@ -2,6 +2,10 @@ package envio
 
 import (
 	"bytes"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/netip"
 	"os"
 	"testing"
 )
@@ -94,3 +98,95 @@ func Test_GetENV(t *testing.T) {
 		equal(t, tt.expect.AI, tt.input.AI)
 	}
 }
+
+func Test_GetENVDefault(t *testing.T) {
+	os.Clearenv()
+
+	in := &struct {
+		AI ai `env:"AI,default=no"`
+	}{}
+
+	equal(t, nil, Get(in))
+	equal(t, ai(-1), in.AI)
+}
+
+type upper string
+
+func (u *upper) UnmarshalText(p []byte) error {
+	if len(p) == 0 {
+		return errors.New("empty")
+	}
+	*u = upper(bytes.ToUpper(p))
+	return nil
+}
+
+func (u upper) MarshalText() ([]byte, error) {
+	return bytes.ToLower([]byte(u)), nil
+}
+
+type pair [2]byte
+
+func (p *pair) UnmarshalBinary(b []byte) error {
+	if len(b) != 2 {
+		return errors.New("invalid length")
+	}
+	copy(p[:], b)
+	return nil
+}
+
+func (p pair) MarshalBinary() ([]byte, error) {
+	return p[:], nil
+}
+
+type marshalers struct {
+	Addr   netip.Addr   `env:"ADDR"`
+	Addrs  []netip.Addr `env:"ADDRS"`
+	Level  slog.Level   `env:"LEVEL"`
+	Big    *big.Int     `env:"BIG"`
+	Upper  upper        `env:"UPPER"`
+	Uppers [2]upper     `env:"UPPERS"`
+	AIs    []ai         `env:"AIS"`
+	Pair   pair         `env:"PAIR"`
+	Raw    pair         `env:"RAW,raw"`
+}
+
+func Test_Marshalers(t *testing.T) {
+	in := &marshalers{
+		Addr:   netip.MustParseAddr("10.0.0.1"),
+		Addrs:  []netip.Addr{netip.MustParseAddr("192.168.0.1"), netip.MustParseAddr("127.0.0.1")},
+		Level:  slog.LevelWarn,
+		Big:    new(big.Int).Lsh(big.NewInt(1), 100),
+		Upper:  "ABC",
+		Uppers: [2]upper{"X", "Y"},
+		AIs:    []ai{1, -1},
+		Pair:   pair{1, 2},
+		Raw:    pair{'o', 'k'},
+	}
+
+	os.Clearenv()
+	equal(t, nil, Set(in))
+
+	sep := string(envSeparator)
+	for _, v := range []env{
+		{name: "ADDR", value: "10.0.0.1"},
+		{name: "ADDRS", value: "192.168.0.1" + sep + "127.0.0.1"},
+		{name: "LEVEL", value: "WARN"},
+		{name: "BIG", value: "1267650600228229401496703205376"},
+		{name: "UPPER", value: "abc"},
+		{name: "UPPERS", value: "x" + sep + "y"},
+		{name: "AIS", value: "yes" + sep + "no"},
+		{name: "PAIR", value: "1" + sep + "2"},
+		{name: "RAW", value: "ok"},
+	} {
+		equal(t, v.value, os.Getenv(v.name))
+	}
+
+	out := new(marshalers)
+	equal(t, nil, Get(out))
+	equal(t, in, out)
+
+	os.Clearenv()
+	equal(t, nil, os.Setenv("PAIR", "1"+sep+"2"+sep+"3"))
+	equal(t, "env: cannot get data into Go struct field marshalers.PAIR of type envio.pair: index out of range", Get(new(marshalers)).Error())
+	os.Clearenv()
+}
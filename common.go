@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 var (
@@ -12,8 +14,57 @@ var (
 	ErrNotSupportType      = errors.New("cannot support type")
 	ErrNilInterface        = errors.New("interface is nil")
 	ErrPointerToUnexported = errors.New("cannot set embedded pointer to unexported struct")
+	ErrTagConflict         = errors.New("conflicting tag options")
+	ErrMissing             = errors.New("the required variable is missing")
+	ErrEmpty               = errors.New("the variable is empty")
+	ErrCycle               = errors.New("reference cycle")
+	ErrFileTooLarge        = errors.New("file is too large")
 )
 
+// FieldError describes a failure to get or set a single variable.
+type FieldError struct {
+	Struct string       // name of the struct type, empty for a value that is not a struct field
+	Field  string       // name of the struct field
+	Name   string       // name of the variable
+	Type   reflect.Type // type of the value
+	Op     string       // description of the operation
+	Err    error        // the underlying error
+
+	tag string
+}
+
+func (e *FieldError) Error() string {
+	if e.Err == ErrMissing {
+		return fmt.Sprintf("%s: the required variable $%s is missing", e.tag, e.Name)
+	}
+	if e.Struct == "" {
+		if e.Field == "" && e.Name != "" {
+			return fmt.Sprintf("%s: cannot %s $%s of type %s: %v", e.tag, e.Op, e.Name, e.Type, e.Err)
+		}
+		return fmt.Sprintf("%s: cannot %s Go value of type %s: %v", e.tag, e.Op, e.Type, e.Err)
+	}
+	return fmt.Sprintf("%s: cannot %s Go struct field %s.%s of type %s: %v", e.tag, e.Op, e.Struct, e.Name, e.Type, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Errors is the list of errors returned when the AllErrors option is used.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
+
 func bitSize(v reflect.Kind) int {
 	switch v {
 	case reflect.Int8, reflect.Uint8:
@@ -22,8 +73,10 @@ func bitSize(v reflect.Kind) int {
 		return 16
 	case reflect.Int32, reflect.Uint32, reflect.Float32:
 		return 32
-	case reflect.Int64, reflect.Uint64, reflect.Float64:
+	case reflect.Int64, reflect.Uint64, reflect.Float64, reflect.Complex64:
 		return 64
+	case reflect.Complex128:
+		return 128
 	case reflect.Int, reflect.Uint, reflect.Uintptr:
 		return 32 << (^uint(0) >> 63)
 	default:
@@ -31,6 +84,49 @@ func bitSize(v reflect.Kind) int {
 	}
 }
 
+// lessValue reports whether a sorts before b, both values must be of the same type.
+// Pointers are compared by the values they point to, nil pointers sort first.
+func lessValue(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.Complex64, reflect.Complex128:
+		ac, bc := a.Complex(), b.Complex()
+		return real(ac) < real(bc) || real(ac) == real(bc) && imag(ac) < imag(bc)
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() && !b.IsNil()
+		}
+		return lessValue(a.Elem(), b.Elem())
+	default:
+		return false
+	}
+}
+
+// orderedKind reports whether the values of the type, or of the type it points to, are ordered by lessValue.
+func orderedKind(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	default:
+		return false
+	}
+}
+
 func isEmptyValue(v reflect.Value) bool {
 	switch v.Kind() {
 	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
@@ -39,7 +135,7 @@ func isEmptyValue(v reflect.Value) bool {
 		return !v.Bool()
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
-		reflect.Float32, reflect.Float64:
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
 		return v.IsZero()
 	case reflect.Interface, reflect.Pointer:
 		return v.IsNil()
@@ -48,24 +144,52 @@ func isEmptyValue(v reflect.Value) bool {
 	}
 }
 
-type context struct {
+type fieldContext struct {
 	structName string
+	prefix     string
 	field      *field
-	err        error
+	// typ is the type of the value being got/set, which is the dynamic type for interfaces
+	// and the element type for pointers, while field.typ is the declared type.
+	typ reflect.Type
+	err error
+	// naming derives the names of fields without a name in the tag.
+	naming func(string) string
+}
+
+func (c *fieldContext) reset() {
+	c.structName = ""
+	c.prefix = ""
+	c.field = new(field)
+	c.typ = nil
+	c.err = nil
 }
 
-func (c *context) setError(tagName, state string, err error) {
-	err = unwrapErr(err)
-	if c.structName == "" {
-		c.err = fmt.Errorf("%s: cannot %s Go value of type %s: %w", tagName, state, c.field.typ, err)
-	} else {
-		c.err = fmt.Errorf("%s: cannot %s Go struct field %s.%s of type %s: %w", tagName, state, c.structName, c.field.name, c.field.typ, err)
+// varName returns the name of the variable of the current field.
+func (c *fieldContext) varName() string {
+	return c.prefix + c.field.envName(c.naming)
+}
+
+func (c *fieldContext) setError(tagName, state string, err error) {
+	typ := c.typ
+	if typ == nil {
+		typ = c.field.typ
+	}
+	c.err = &FieldError{
+		Struct: c.structName,
+		Field:  c.field.goName,
+		Name:   c.varName(),
+		Type:   typ,
+		Op:     state,
+		Err:    unwrapErr(err),
+		tag:    tagName,
 	}
 }
 
+// unwrapErr strips the function name and the input from parse errors of the strconv package,
+// since the error message already names the variable.
 func unwrapErr(err error) error {
-	if ew := errors.Unwrap(err); ew != nil {
-		return ew
+	if ne, ok := err.(*strconv.NumError); ok {
+		return ne.Err
 	}
 	return err
 }
@@ -65,6 +65,16 @@ func Test_bitSize(t *testing.T) {
 			reflectKind: reflect.Uint64,
 			expect:      64,
 		},
+		{
+			name:        "complex64",
+			reflectKind: reflect.Complex64,
+			expect:      64,
+		},
+		{
+			name:        "complex128",
+			reflectKind: reflect.Complex128,
+			expect:      128,
+		},
 		{
 			name:        "int",
 			reflectKind: reflect.Int,
@@ -184,12 +194,12 @@ func Test_contextSetError(t *testing.T) {
 
 	var tests = []struct {
 		name   string
-		ctx    context
+		ctx    fieldContext
 		expect error
 	}{
 		{
 			name: "error for structs",
-			ctx: context{
+			ctx: fieldContext{
 				structName: "structName",
 				field: &field{
 					name: "fieldName",
@@ -201,7 +211,7 @@ func Test_contextSetError(t *testing.T) {
 		},
 		{
 			name: "error for simple types",
-			ctx: context{
+			ctx: fieldContext{
 				structName: "",
 				field: &field{
 					typ: reflect.TypeOf(true),
@@ -0,0 +1,137 @@
+package envio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// DiffStatus describes how the value of a variable in a source differs from the value Set would write.
+type DiffStatus int
+
+const (
+	// DiffMissing means that the variable is not present in the source.
+	DiffMissing DiffStatus = iota
+	// DiffEqual means that the variable holds the value Set would write.
+	DiffEqual
+	// DiffDifferent means that the variable holds another value.
+	DiffDifferent
+	// DiffStale means that the variable is present in the source and Set would remove it,
+	// as it does for the elements beyond the end of a list of structs.
+	DiffStale
+)
+
+// String returns the name of the status.
+func (d DiffStatus) String() string {
+	switch d {
+	case DiffMissing:
+		return "missing"
+	case DiffEqual:
+		return "equal"
+	case DiffDifferent:
+		return "different"
+	case DiffStale:
+		return "stale"
+	default:
+		return fmt.Sprintf("DiffStatus(%d)", int(d))
+	}
+}
+
+// Difference describes a variable that Set would write or remove.
+type Difference struct {
+	// Name is the name of the variable.
+	Name string
+	// Current is the value of the variable in the source, empty if it is missing.
+	Current string
+	// Desired is the value Set would write, empty if it is stale.
+	Desired string
+	Status  DiffStatus
+}
+
+// Diff compares the variables that Set would set from v with the environment and returns them sorted by name,
+// along with the stale variables Set would remove.
+func Diff(v any, opts ...Option) ([]Difference, error) {
+	return DiffFrom(Process{}, v, opts...)
+}
+
+// DiffFrom is like Diff but compares the variables with the source.
+func DiffFrom(src Source, v any, opts ...Option) ([]Difference, error) {
+	rec := &recorder{set: make(Map)}
+
+	ctx := context.Background()
+	c := *newEngine(opts)
+	c.sink, c.source = rec, src
+	if err := c.set(ctx, v); err != nil {
+		return nil, err
+	}
+
+	diffs := make([]Difference, 0, len(rec.set)+len(rec.removed))
+	for k, desired := range rec.set {
+		cur, ok, err := lookup(ctx, src, k)
+		if err != nil {
+			return nil, err
+		}
+
+		d := Difference{Name: k, Current: cur, Desired: desired, Status: DiffDifferent}
+		switch {
+		case !ok:
+			d.Status = DiffMissing
+		case cur == d.Desired:
+			d.Status = DiffEqual
+		}
+		diffs = append(diffs, d)
+	}
+	for _, k := range rec.removed {
+		cur, _, err := lookup(ctx, src, k)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, Difference{Name: k, Current: cur, Status: DiffStale})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	return diffs, nil
+}
+
+// recorder is the sink of DiffFrom, which records the variables Set would set and remove instead of changing them.
+type recorder struct {
+	set     Map
+	removed []string
+}
+
+// Set records the variable.
+func (r *recorder) Set(key, value string) error {
+	r.set[key] = value
+	return nil
+}
+
+// Unset records the removal of the variable.
+func (r *recorder) Unset(key string) error {
+	r.removed = append(r.removed, key)
+	return nil
+}
+
+// Patch is like Set but only sets the variables that are missing or hold other values,
+// and returns the names of the variables it set or removed in that order.
+// Like Set, it removes the stale variables of lists and maps of structs.
+func Patch(v any, opts ...Option) ([]string, error) {
+	return PatchTo(Process{}, v, opts...)
+}
+
+// PatchTo is like Patch but sets the variables to the sink, which must also be a Source.
+func PatchTo(sink Sink, v any, opts ...Option) ([]string, error) {
+	src, ok := sink.(Source)
+	if !ok {
+		return nil, fmt.Errorf("%s: the sink is not a source", name)
+	}
+
+	c := *newEngine(opts)
+	c.sink, c.source = sink, src
+
+	s := c.newSetState(context.Background())
+	defer setStatePool.Put(s)
+
+	s.patching = true
+	s.set(v)
+	return s.written, s.err
+}
@@ -0,0 +1,114 @@
+package envio
+
+import (
+	"errors"
+	"testing"
+)
+
+type drift struct {
+	Host  string  `env:"HOST"`
+	Port  int     `env:"PORT"`
+	Debug bool    `env:"DEBUG"`
+	Token *string `env:"TOKEN"`
+	Nodes []struct {
+		Addr string `env:"ADDR"`
+	} `env:"NODE"`
+}
+
+func newDrift() *drift {
+	d := &drift{Host: "db", Port: 5432}
+	d.Nodes = append(d.Nodes, struct {
+		Addr string `env:"ADDR"`
+	}{Addr: "a"})
+	return d
+}
+
+func Test_Diff(t *testing.T) {
+	src := Map{"HOST": "db", "PORT": "5433", "NODE_0_ADDR": "a", "NODE_COUNT": "1"}
+
+	diffs, err := DiffFrom(src, newDrift())
+	equal(t, nil, err)
+	equal(t, []Difference{
+		{Name: "DEBUG", Desired: "false", Status: DiffMissing},
+		{Name: "HOST", Current: "db", Desired: "db", Status: DiffEqual},
+		{Name: "NODE_0_ADDR", Current: "a", Desired: "a", Status: DiffEqual},
+		{Name: "NODE_COUNT", Current: "1", Desired: "1", Status: DiffEqual},
+		{Name: "PORT", Current: "5433", Desired: "5432", Status: DiffDifferent},
+	}, diffs)
+
+	equal(t, "missing", DiffMissing.String())
+	equal(t, "stale", DiffStale.String())
+	equal(t, "different", DiffDifferent.String())
+	equal(t, "DiffStatus(7)", DiffStatus(7).String())
+
+	_, err = DiffFrom(src, nil)
+	equal(t, true, err != nil)
+}
+
+func Test_Patch(t *testing.T) {
+	sink := Map{"HOST": "db", "PORT": "5433", "NODE_0_ADDR": "a", "NODE_1_ADDR": "b", "NODE_COUNT": "2"}
+
+	names, err := PatchTo(sink, newDrift())
+	equal(t, nil, err)
+	equal(t, []string{"PORT", "DEBUG", "NODE_COUNT", "NODE_1_ADDR"}, names)
+	equal(t, Map{"HOST": "db", "PORT": "5432", "DEBUG": "false", "NODE_0_ADDR": "a", "NODE_COUNT": "1"}, sink)
+
+	names, err = PatchTo(sink, newDrift())
+	equal(t, nil, err)
+	equal(t, []string(nil), names)
+
+	_, err = PatchTo(sinkOnly{}, newDrift())
+	equal(t, "env: the sink is not a source", err.Error())
+
+	t.Setenv("HOST", "db")
+	t.Setenv("PORT", "1")
+	names, err = Patch(&struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}{Host: "db", Port: 2})
+	equal(t, nil, err)
+	equal(t, []string{"PORT"}, names)
+
+	diffs, err := Diff(&struct {
+		Port int `env:"PORT"`
+	}{Port: 2})
+	equal(t, nil, err)
+	equal(t, DiffEqual, diffs[0].Status)
+}
+
+func Test_DiffStale(t *testing.T) {
+	type list struct {
+		U []upstream `env:"U"`
+	}
+
+	in := &list{U: []upstream{{Host: "x", Port: 80}}}
+	src := Map{"U_0_HOST": "x", "U_0_PORT": "80", "U_1_HOST": "b", "U_3_PORT": "1"}
+
+	diffs, err := DiffFrom(src, in)
+	equal(t, nil, err)
+	equal(t, []Difference{
+		{Name: "U_0_HOST", Current: "x", Desired: "x", Status: DiffEqual},
+		{Name: "U_0_PORT", Current: "80", Desired: "80", Status: DiffEqual},
+		{Name: "U_1_HOST", Current: "b", Status: DiffStale},
+		{Name: "U_3_PORT", Current: "1", Status: DiffStale},
+	}, diffs)
+	equal(t, 4, len(src))
+
+	names, err := PatchTo(src, in)
+	equal(t, nil, err)
+	equal(t, []string{"U_1_HOST", "U_3_PORT"}, names)
+	equal(t, Map{"U_0_HOST": "x", "U_0_PORT": "80"}, src)
+
+	t.Setenv("U_0_HOST", "x")
+	t.Setenv("U_0_PORT", "80")
+	t.Setenv("U_1_HOST", "b")
+	names, err = Patch(in)
+	equal(t, nil, err)
+	equal(t, []string{"U_1_HOST"}, names)
+	_, ok := Process{}.Lookup("U_1_HOST")
+	equal(t, false, ok)
+}
+
+type sinkOnly struct{}
+
+func (sinkOnly) Set(string, string) error { return errors.New("unexpected") }
@@ -0,0 +1,332 @@
+package envio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Policy controls how LoadDotenv treats variables that are already set.
+type Policy int
+
+const (
+	// KeepExisting keeps the values of variables that are already set.
+	KeepExisting Policy = iota
+	// Override replaces the values of variables that are already set.
+	Override
+)
+
+// SyntaxError describes a malformed line of a .env file.
+type SyntaxError struct {
+	Line int    // line number, starting at 1
+	Msg  string // description of the error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s: .env line %d: %s", name, e.Line, e.Msg)
+}
+
+// ParseDotenv parses variables in the .env format.
+//
+// Blank lines and lines starting with '#' are ignored, and a line may start with 'export'.
+// Values may be unquoted, in which case an inline comment starts at " #",
+// or quoted with single quotes, double quotes or backticks, in which case they may span several lines.
+// Only double-quoted values support the escape sequences \n, \r, \t, \\, \", \$ and \'.
+func ParseDotenv(r io.Reader) (Map, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &dotenvParser{src: strings.ReplaceAll(string(data), "\r\n", "\n"), line: 1}
+	m := make(Map)
+	for {
+		key, value, ok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return m, nil
+		}
+		m[key] = value
+	}
+}
+
+// ReadDotenv reads and parses the .env files, values from later files take precedence.
+func ReadDotenv(paths ...string) (Map, error) {
+	m := make(Map)
+	for _, path := range paths {
+		fm, err := readDotenv(path)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fm {
+			m[k] = v
+		}
+	}
+	return m, nil
+}
+
+func readDotenv(path string) (Map, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m, err := ParseDotenv(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// LoadDotenv reads the .env files and sets their variables in the environment of the current process.
+func LoadDotenv(policy Policy, paths ...string) error {
+	m, err := ReadDotenv(paths...)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range m {
+		if _, ok := os.LookupEnv(k); ok && policy == KeepExisting {
+			continue
+		}
+		if err = os.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDotenv reads the .env file and gets its values to the value pointed to by v,
+// without changing the environment of the current process.
+func GetDotenv(path string, v any, opts ...Option) error {
+	m, err := readDotenv(path)
+	if err != nil {
+		return err
+	}
+	return GetFrom(m, v, opts...)
+}
+
+// WriteDotenv writes values from v to w in the .env format, sorted by name.
+// Values are quoted when needed, so that ParseDotenv reads them back unchanged.
+func WriteDotenv(w io.Writer, v any, opts ...Option) error {
+	m := make(Map)
+	if err := SetTo(m, v, opts...); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	bw := bufio.NewWriter(w)
+	for _, k := range keys {
+		bw.WriteString(k)
+		bw.WriteByte('=')
+		bw.WriteString(quoteDotenv(m[k]))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// quoteDotenv returns the value as is if it is safe to leave it unquoted, otherwise double-quoted.
+func quoteDotenv(s string) string {
+	safe := true
+	for i := 0; i < len(s) && safe; i++ {
+		c := s[i]
+		safe = 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("_-.,:;/=+@%", c) >= 0
+	}
+	if safe {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '"', '\\', '$':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+type dotenvParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *dotenvParser) errorf(format string, args ...any) error {
+	return &SyntaxError{Line: p.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next returns the next variable, ok is false at the end of the input.
+func (p *dotenvParser) next() (key, value string, ok bool, err error) {
+	for p.pos < len(p.src) {
+		line := p.src[p.pos:]
+		if i := strings.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i]
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' {
+			p.skipLine(len(line))
+			continue
+		}
+
+		p.pos += len(line) - len(strings.TrimLeft(line, " \t"))
+
+		if rest := p.src[p.pos:]; strings.HasPrefix(rest, "export") && len(rest) > 6 && (rest[6] == ' ' || rest[6] == '\t') {
+			p.pos += 6
+			p.skipSpaces()
+		}
+
+		eq := strings.IndexAny(p.src[p.pos:], "=\n")
+		if eq < 0 || p.src[p.pos+eq] != '=' {
+			return "", "", false, p.errorf("missing '=' after the variable name")
+		}
+
+		key = strings.TrimRight(p.src[p.pos:p.pos+eq], " \t")
+		if !validDotenvKey(key) {
+			return "", "", false, p.errorf("invalid variable name %q", key)
+		}
+		p.pos += eq + 1
+		p.skipSpaces()
+
+		if value, err = p.value(); err != nil {
+			return "", "", false, err
+		}
+		return key, value, true, nil
+	}
+	return "", "", false, nil
+}
+
+// value parses the value at the current position and moves to the beginning of the next line.
+func (p *dotenvParser) value() (string, error) {
+	if p.pos >= len(p.src) {
+		return "", nil
+	}
+
+	switch q := p.src[p.pos]; q {
+	case '\'', '"', '`':
+		start := p.line
+		p.pos++
+
+		var b strings.Builder
+		for {
+			if p.pos >= len(p.src) {
+				p.line = start
+				return "", p.errorf("unterminated quoted value")
+			}
+
+			c := p.src[p.pos]
+			p.pos++
+
+			switch {
+			case c == q:
+				if err := p.endOfLine(); err != nil {
+					return "", err
+				}
+				return b.String(), nil
+			case c == '\n':
+				p.line++
+				b.WriteByte(c)
+			case c == '\\' && q == '"' && p.pos < len(p.src):
+				e := p.src[p.pos]
+				p.pos++
+				switch e {
+				case 'n':
+					b.WriteByte('\n')
+				case 'r':
+					b.WriteByte('\r')
+				case 't':
+					b.WriteByte('\t')
+				case '\\', '"', '$', '\'':
+					b.WriteByte(e)
+				default:
+					b.WriteByte(c)
+					b.WriteByte(e)
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+	default:
+		// Include the preceding character to recognize a comment right after the spaces following '='.
+		line := p.src[p.pos-1:]
+		if i := strings.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i]
+		}
+		p.skipLine(len(line) - 1)
+
+		// An inline comment starts with '#' preceded by a whitespace.
+		for i := 1; i < len(line); i++ {
+			if line[i] == '#' && (line[i-1] == ' ' || line[i-1] == '\t') {
+				line = line[:i]
+				break
+			}
+		}
+		return strings.TrimRight(line[1:], " \t"), nil
+	}
+}
+
+// endOfLine checks that only whitespaces or a comment follow a quoted value and moves to the next line.
+func (p *dotenvParser) endOfLine() error {
+	p.skipSpaces()
+
+	line := p.src[p.pos:]
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	if line != "" && line[0] != '#' {
+		return p.errorf("unexpected characters %q after the quoted value", line)
+	}
+	p.skipLine(len(line))
+	return nil
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipLine moves past n bytes of the current line and its line break.
+func (p *dotenvParser) skipLine(n int) {
+	p.pos += n
+	if p.pos < len(p.src) {
+		p.pos++
+		p.line++
+	}
+}
+
+func validDotenvKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c == '.' || c == '-' || i > 0 && '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package envio
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type dotenv struct {
+	A string   `env:"A"`
+	B string   `env:"B"`
+	C []string `env:"C"`
+	D int      `env:"D"`
+}
+
+func Test_ParseDotenv(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect Map
+		err    error
+	}{
+		{
+			name: "comments and blank lines",
+			input: `# comment
+
+A=1
+  # indented comment
+B=2
+`,
+			expect: Map{"A": "1", "B": "2"},
+		},
+		{
+			name:   "export prefix and spaces",
+			input:  "export A=1\n  export\tB = 2  \nexported=3",
+			expect: Map{"A": "1", "B": "2", "exported": "3"},
+		},
+		{
+			name:   "inline comments",
+			input:  "A=value # comment\nB=val#ue\nC= # empty\nD=",
+			expect: Map{"A": "value", "B": "val#ue", "C": "", "D": ""},
+		},
+		{
+			name:   "single quotes",
+			input:  `A='a "b" \n # c' # comment`,
+			expect: Map{"A": `a "b" \n # c`},
+		},
+		{
+			name:   "double quotes",
+			input:  `A="a\tb\n\"c\" \\ \$HOME \x"`,
+			expect: Map{"A": "a\tb\n\"c\" \\ $HOME \\x"},
+		},
+		{
+			name:   "backticks",
+			input:  "A=`it's \"quoted\"`",
+			expect: Map{"A": `it's "quoted"`},
+		},
+		{
+			name:   "multi-line values",
+			input:  "A=\"line 1\nline 2\"\nB='x\r\ny'\r\nC=3",
+			expect: Map{"A": "line 1\nline 2", "B": "x\ny", "C": "3"},
+		},
+		{
+			name:  "missing equal sign",
+			input: "A=1\nB\nC=3",
+			err:   errors.New("env: .env line 2: missing '=' after the variable name"),
+		},
+		{
+			name:  "invalid name",
+			input: "A=1\n\n1A=2",
+			err:   errors.New(`env: .env line 3: invalid variable name "1A"`),
+		},
+		{
+			name:  "unterminated quote",
+			input: "A=1\nB=\"2\n\nC=3",
+			err:   errors.New("env: .env line 2: unterminated quoted value"),
+		},
+		{
+			name:  "characters after quote",
+			input: "A='1\n2' 3",
+			err:   errors.New(`env: .env line 2: unexpected characters "3" after the quoted value`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseDotenv(strings.NewReader(tt.input))
+			if tt.err != nil {
+				equal(t, tt.err.Error(), err.Error())
+				return
+			}
+			equal(t, nil, err)
+			equal(t, tt.expect, m)
+		})
+	}
+}
+
+func Test_Dotenv(t *testing.T) {
+	in := &dotenv{
+		A: "plain",
+		B: "with \"quotes\", $dollars and\nnew lines # not a comment",
+		C: []string{"x", "y z"},
+		D: 7,
+	}
+
+	buf := new(bytes.Buffer)
+	equal(t, nil, WriteDotenv(buf, in))
+	equal(t, "A=plain\n"+
+		`B="with \"quotes\", \$dollars and\nnew lines # not a comment"`+"\n"+
+		`C="x`+string(envSeparator)+`y z"`+"\n"+
+		"D=7\n", buf.String())
+
+	path := filepath.Join(t.TempDir(), ".env")
+	equal(t, nil, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	out := new(dotenv)
+	equal(t, nil, GetDotenv(path, out))
+	equal(t, in, out)
+
+	os.Clearenv()
+	equal(t, nil, os.Setenv("A", "existing"))
+
+	equal(t, nil, LoadDotenv(KeepExisting, path))
+	equal(t, "existing", os.Getenv("A"))
+	equal(t, "7", os.Getenv("D"))
+
+	equal(t, nil, LoadDotenv(Override, path))
+	equal(t, "plain", os.Getenv("A"))
+
+	os.Clearenv()
+}
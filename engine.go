@@ -1,7 +1,10 @@
 package envio
 
 import (
+	"context"
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -9,28 +12,173 @@ import (
 const name = "env"
 
 var e = &engine{
-	separator: []byte{envSeparator},
+	separator:         []byte{envSeparator},
+	innerSeparator:    []byte{','},
+	pairSeparator:     []byte{','},
+	keyValueSeparator: []byte{'='},
+	source:            Process{},
+	sink:              Process{},
+	maxFileSize:       defaultMaxFileSize,
 }
 
 // Set sets values from v to environment variables.
 // If v is nil, Set returns a setter error.
-func Set(v any) error {
-	return e.set(v)
+func Set(v any, opts ...Option) error {
+	return newEngine(opts).set(context.Background(), v)
 }
 
 // Get gets values from environment variables to the value pointed to by v.
 // If v is nil or not a pointer, Get returns a getter error.
-func Get(v any) error {
-	return e.get(v)
+func Get(v any, opts ...Option) error {
+	return newEngine(opts).get(context.Background(), v)
+}
+
+// Option configures the engine for a single call.
+type Option func(*engine)
+
+// AutoPrefix makes the name of a nested struct field followed by the delimiter
+// the prefix of the names of its fields, so that the field `DB` of a struct type
+// with the field `HOST` is got/set by name 'DB_HOST' when the delimiter is "_".
+// An explicit prefix in the tag takes precedence, embedded structs are never prefixed automatically.
+func AutoPrefix(delimiter string) Option {
+	return func(e *engine) {
+		e.autoPrefix = true
+		e.delimiter = delimiter
+	}
+}
+
+// MapSeparators sets the separator between the pairs of a map and
+// the separator between the key and the value of a pair, "," and "=" by default.
+func MapSeparators(pair, keyValue string) Option {
+	return func(e *engine) {
+		e.pairSeparator = []byte(pair)
+		e.keyValueSeparator = []byte(keyValue)
+	}
+}
+
+// AllErrors makes Get continue after a field fails and return all errors at once as Errors.
+// Fields that are got successfully are still filled in.
+func AllErrors() Option {
+	return func(e *engine) {
+		e.allErrors = true
+	}
+}
+
+// ExtendedDurations makes time.Duration values also accept the units "d" (24h) and "w" (7d), as in "1w2d12h".
+func ExtendedDurations() Option {
+	return func(e *engine) {
+		e.durationDays = true
+	}
+}
+
+// Expand expands references to other variables in all values, as if every field had the expand tag option.
+func Expand() Option {
+	return func(e *engine) {
+		e.expand = true
+	}
+}
+
+// FileFallback makes a missing variable NAME be read from the file at the path held by NAME_FILE,
+// as with secrets mounted by Docker and Kubernetes.
+func FileFallback() Option {
+	return func(e *engine) {
+		e.fileFallback = true
+	}
+}
+
+// TrimFileNewline removes a trailing newline from values read from files.
+func TrimFileNewline() Option {
+	return func(e *engine) {
+		e.trimNewline = true
+	}
+}
+
+// MaxFileSize sets the limit of the size of files that values are read from, 1 MiB by default.
+func MaxFileSize(n int64) Option {
+	return func(e *engine) {
+		e.maxFileSize = n
+	}
+}
+
+// Separator sets the separator between the elements of arrays and slices,
+// ':' on Unix and ';' on Windows by default.
+func Separator(sep string) Option {
+	return func(e *engine) {
+		e.separator = []byte(sep)
+	}
+}
+
+// InnerSeparator sets the separator between the elements of lists nested in lists, such as [][]int, "," by default.
+func InnerSeparator(sep string) Option {
+	return func(e *engine) {
+		e.innerSeparator = []byte(sep)
+	}
 }
 
 type engine struct {
-	separator []byte
+	separator         []byte
+	innerSeparator    []byte
+	pairSeparator     []byte
+	keyValueSeparator []byte
+	escaping          EscapeMode
+	autoPrefix        bool
+	delimiter         string
+	allErrors         bool
+	durationDays      bool
+	expand            bool
+	fileFallback      bool
+	trimNewline       bool
+	maxFileSize       int64
+	keepMandatory     bool
+	onlyUnchanged     bool
+	naming            func(string) string
+	ignoreCase        bool
+	source            Source
+	sink              Sink
+}
+
+func newEngine(opts []Option) *engine {
+	if len(opts) == 0 {
+		return e
+	}
+
+	c := *e
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &c
 }
 
 type functions struct {
 	setterFunc
 	getterFunc
+	nested bool
+	// indexed reports whether the elements of a list or a map are structs got/set from indexed variables.
+	indexed bool
+}
+
+// procs are the parser and the formatter of a type that is got/set as a single value.
+type procs struct {
+	get getProcFunc
+	set setProcFunc
+}
+
+// builtinTypes are the types that are handled specially regardless of their kind.
+var builtinTypes = map[reflect.Type]procs{
+	durationType:     {get: durationProc, set: durationFormat},
+	timeType:         {get: timeProc, set: timeFormat},
+	locationType:     {get: locationProc, set: locationFormat},
+	bigIntType:       {get: bigIntProc, set: bigIntFormat},
+	bigFloatType:     {get: bigFloatProc, set: bigFloatFormat},
+	bigRatType:       {get: bigRatProc, set: bigRatFormat},
+	urlType:          {get: urlProc, set: urlFormat},
+	ipType:           {get: ipProc, set: ipFormat},
+	ipNetType:        {get: ipNetProc, set: ipNetFormat},
+	hardwareAddrType: {get: hardwareAddrProc, set: hardwareAddrFormat},
+	addrType:         {get: addrProc, set: addrFormat},
+	addrPortType:     {get: addrPortProc, set: addrPortFormat},
+	prefixType:       {get: prefixProc, set: prefixFormat},
+	regexpType:       {get: regexpProc, set: regexpFormat},
 }
 
 var functionsCache sync.Map // map[reflect.Type]*functions
@@ -48,6 +196,13 @@ func (e *engine) cachedFunctions(t reflect.Type) *functions {
 // typeFunctions returns functions for a type.
 func (e *engine) typeFunctions(t reflect.Type) *functions {
 	f := new(functions)
+
+	if p, ok := typeProcs(t); ok {
+		f.setterFunc = scalarSetter(p.set)
+		f.getterFunc = scalarGetter(p.get)
+		return f
+	}
+
 	switch t.Kind() {
 	case reflect.Bool:
 		f.setterFunc = boolSetter
@@ -61,36 +216,73 @@ func (e *engine) typeFunctions(t reflect.Type) *functions {
 	case reflect.Float32, reflect.Float64:
 		f.setterFunc = floatSetter
 		f.getterFunc = floatGetter
-	case reflect.Array:
-		f.setterFunc = sliceSetter(t)
-		f.getterFunc = arrayGetter(t)
+	case reflect.Complex64, reflect.Complex128:
+		f.setterFunc = complexSetter
+		f.getterFunc = complexGetter
+	case reflect.Array, reflect.Slice:
+		// Structs are got/set field by field from indexed variables, unless their pointers are built-in types.
+		if e.isNested(t.Elem()) && getProc(t.Elem()) == nil {
+			f.setterFunc = indexedSetter(t)
+			f.getterFunc = indexedGetter(t)
+			f.indexed = true
+		} else if t.Kind() == reflect.Array {
+			f.setterFunc = sliceSetter(t)
+			f.getterFunc = arrayGetter(t)
+		} else {
+			f.setterFunc = sliceSetter(t)
+			f.getterFunc = sliceGetter(t)
+		}
 	case reflect.Interface:
 		f.setterFunc = interfaceSetter
 		f.getterFunc = interfaceGetter
+	case reflect.Map:
+		// Structs are got/set field by field from variables named after the keys.
+		if e.isNested(t.Elem()) && getProc(t.Elem()) == nil {
+			f.setterFunc = keyedSetter(t)
+			f.getterFunc = keyedGetter(t)
+			f.indexed = true
+		} else {
+			f.setterFunc = mapSetter(t)
+			f.getterFunc = mapGetter(t)
+		}
 	case reflect.Pointer:
 		f.setterFunc = pointerSetter
 		f.getterFunc = pointerGetter
-	case reflect.Slice:
-		f.setterFunc = sliceSetter(t)
-		f.getterFunc = sliceGetter(t)
 	case reflect.String:
 		f.setterFunc = stringSetter
 		f.getterFunc = stringGetter
 	case reflect.Struct:
 		f.setterFunc = structSetter
 		f.getterFunc = structGetter
+		f.nested = true
 	default:
 		f.setterFunc = unsupportedTypeSetter
 		f.getterFunc = unsupportedTypeGetter
 	}
 
+	// Types that can get/set themselves take priority over the text and binary encodings,
+	// which take priority over the kind of the type.
 	if t.Kind() != reflect.Pointer {
 		p := reflect.PointerTo(t)
-		if p.Implements(setter) {
+		switch {
+		case p.Implements(setter):
 			f.setterFunc = setSetter
+			f.nested, f.indexed = false, false
+		case p.Implements(textMarshaler):
+			f.setterFunc = marshalerSetter(t, f.setterFunc)
+			f.nested, f.indexed = false, false
+		case p.Implements(binaryMarshaler):
+			f.setterFunc = marshalerSetter(t, f.setterFunc)
 		}
-		if p.Implements(getter) {
+		switch {
+		case p.Implements(getter):
 			f.getterFunc = getGetter
+			f.nested, f.indexed = false, false
+		case p.Implements(textUnmarshaler):
+			f.getterFunc = unmarshalerGetter(t, f.getterFunc)
+			f.nested, f.indexed = false, false
+		case p.Implements(binaryUnmarshaler):
+			f.getterFunc = unmarshalerGetter(t, f.getterFunc)
 		}
 	}
 
@@ -101,11 +293,32 @@ func (e *engine) typeFunctions(t reflect.Type) *functions {
 type field struct {
 	index     int
 	name      string
+	hasName   bool
+	goName    string
 	typ       reflect.Type
 	mandatory bool
+	notEmpty  bool
 	raw       bool
+	def       string
+	hasDef    bool
+	layout    string
+	expand    bool
+	file      bool
+	sep       []byte
+	innerSep  []byte
+	kvSep     []byte
+	trim      bool
+	desc      string
+	base      int
+	hasBase   bool
+	rules     []rule
 	functions *functions
+	prefix    string
+	hasPrefix bool
+	nested    bool
+	indexed   bool
 	embedded  structFields
+	err       error
 }
 
 type structFields []*field
@@ -131,9 +344,17 @@ func (e *engine) typeFields(t reflect.Type) structFields {
 		ft := sf.Type
 
 		f := &field{
-			index: i,
-			name:  sf.Name,
-			typ:   ft,
+			index:  i,
+			name:   sf.Name,
+			goName: sf.Name,
+			typ:    ft,
+		}
+
+		tag, hasTag := sf.Tag.Lookup(name)
+
+		// Ignore the field if the tag has a skip value.
+		if tag == "-" {
+			continue
 		}
 
 		if sf.Anonymous {
@@ -153,6 +374,11 @@ func (e *engine) typeFields(t reflect.Type) structFields {
 				continue
 			}
 
+			// Embedded fields are flattened, only an explicit prefix applies to them.
+			if hasTag {
+				f.parseTag(t, sf, tag)
+			}
+
 			fs = append(fs, f)
 			continue
 		} else if !sf.IsExported() {
@@ -160,31 +386,137 @@ func (e *engine) typeFields(t reflect.Type) structFields {
 			continue
 		}
 
-		if tag, ok := sf.Tag.Lookup(name); ok {
-			// Ignore the field if the tag has a skip value.
-			if tag == "-" {
-				continue
-			}
-
-			val := strings.Split(tag, ",")
-
-			if len(val) != 0 {
-				f.name = val[0]
+		if desc, ok := sf.Tag.Lookup(descTag); ok {
+			f.desc = desc
+		}
 
-				for _, v := range val {
-					switch v {
-					case "m":
-						f.mandatory = true
-					case "raw":
-						f.raw = true
-					}
-				}
-			}
+		if hasTag {
+			f.parseTag(t, sf, tag)
 		}
 
 		f.functions = e.cachedFunctions(ft)
+		f.nested = e.isNested(ft)
+		f.indexed = e.isIndexed(ft)
 		fs = append(fs, f)
 	}
 
 	return fs
 }
+
+// parseTag fills the field with the name and options from the tag.
+func (f *field) parseTag(t reflect.Type, sf reflect.StructField, tag string) {
+	val := splitTag(tag)
+
+	if val[0] != "" {
+		f.name, f.hasName = val[0], true
+	}
+
+	for _, v := range val[1:] {
+		opt, arg, _ := strings.Cut(v, "=")
+		switch opt {
+		case "m":
+			f.mandatory = true
+		case "notempty":
+			f.notEmpty = true
+		case "raw":
+			f.raw = true
+		case "default":
+			f.def, f.hasDef = arg, true
+		case "prefix":
+			f.prefix, f.hasPrefix = arg, true
+		case "layout":
+			f.layout = arg
+		case "expand":
+			f.expand = true
+		case "file":
+			f.file = true
+		case "sep":
+			f.sep = []byte(arg)
+		case "innersep":
+			f.innerSep = []byte(arg)
+		case "kvsep":
+			f.kvSep = []byte(arg)
+		case "trim":
+			f.trim = true
+		case "desc":
+			f.desc = arg
+		case "base":
+			base, err := strconv.Atoi(arg)
+			if err != nil || base == 1 || base < 0 || base > 36 {
+				f.err = fmt.Errorf("%s: invalid base %q in Go struct field %s.%s", name, arg, t.Name(), sf.Name)
+			}
+			f.base, f.hasBase = base, true
+		case "min", "max", "oneof", "regex", "nonzero", "url", "hostport", "scheme":
+			r, err := newRule(sf.Type, opt, arg)
+			if err != nil {
+				f.err = invalidRule(t, sf, v, err)
+			}
+			f.rules = append(f.rules, r)
+		}
+	}
+
+	if f.mandatory && f.hasDef {
+		f.err = tagConflict(t, sf, "m", "default")
+	}
+}
+
+// isNested reports whether the values of the type are got/set field by field.
+func (e *engine) isNested(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && e.cachedFunctions(t).nested
+}
+
+// isIndexed reports whether the values of the type are lists or maps of structs got/set from indexed variables.
+func (e *engine) isIndexed(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return e.cachedFunctions(t).indexed
+	default:
+		return false
+	}
+}
+
+// fieldPrefix returns the prefix that the field adds to the names of its nested fields.
+func (e *engine) fieldPrefix(f *field) string {
+	if f.hasPrefix {
+		return f.prefix
+	}
+	if e.autoPrefix && f.nested {
+		return f.envName(e.naming) + e.delimiter
+	}
+	return ""
+}
+
+// splitTag splits the tag value into its comma-separated parts.
+// Commas inside single quotes do not split the tag, and the quotes themselves are removed,
+// so `env:"LIST,default='a,b'"` yields the parts "LIST" and "default=a,b".
+func splitTag(tag string) []string {
+	var (
+		parts  []string
+		buf    strings.Builder
+		quoted bool
+	)
+
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case c == '\'':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			parts = append(parts, buf.String())
+			buf.Reset()
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	return append(parts, buf.String())
+}
+
+func tagConflict(t reflect.Type, sf reflect.StructField, a, b string) error {
+	return fmt.Errorf("%s: %w: %q and %q in Go struct field %s.%s", name, ErrTagConflict, a, b, t.Name(), sf.Name)
+}
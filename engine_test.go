@@ -3,6 +3,7 @@ package envio
 import (
 	"errors"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -43,6 +44,47 @@ type slcArr struct {
 	BytesRaw []byte `env:"ENV_BYTES_RAW,raw"`
 }
 
+type defaults struct {
+	Port  int      `env:"PORT,default=8080"`
+	Hosts []string `env:"HOSTS,default='a,b'"`
+	Level *uint    `env:"LEVEL,default=3"`
+	Name  string   `env:",default=app"`
+}
+
+type conflict struct {
+	A string `env:"ENV_A,m,default=a"`
+}
+
+type prefixed struct {
+	DB    nested  `env:",prefix=DB_"`
+	Cache *nested `env:"CACHE,prefix=CACHE_"`
+	Deep  struct {
+		Inner struct {
+			V string
+		} `env:",prefix=INNER_"`
+	} `env:",prefix=DEEP_"`
+	nested `env:",prefix=EMB_"`
+}
+
+type autoPrefixed struct {
+	DB    simple
+	Cache *simple `env:"CACHE"`
+	Plain simple  `env:",prefix="`
+	simple
+}
+
+type maps struct {
+	Labels map[string]string `env:"LABELS"`
+	Limits map[int]*float64  `env:"LIMITS"`
+}
+
+type presence struct {
+	A string  `env:"ENV_A,m"`
+	B string  `env:"ENV_B,notempty"`
+	C *string `env:"ENV_C"`
+	D *int    `env:"ENV_D,default=1"`
+}
+
 type env struct {
 	name  string
 	value string
@@ -183,6 +225,7 @@ func Test_SetNoOverwrite(t *testing.T) {
 
 func Test_Get(t *testing.T) {
 	a := 28
+	var level uint = 3
 
 	tests := []struct {
 		name   string
@@ -310,6 +353,25 @@ func Test_Get(t *testing.T) {
 				BytesRaw: []byte{65, 66, 67, 68},
 			},
 		},
+		{
+			name:   "default values",
+			input:  new(defaults),
+			expect: &defaults{Port: 8080, Hosts: []string{"a,b"}, Level: &level, Name: "app"},
+		},
+		{
+			name: "default values are overridden",
+			envs: []env{
+				{name: "PORT", value: "80"},
+				{name: "Name", value: "svc"},
+			},
+			input:  new(defaults),
+			expect: &defaults{Port: 80, Hosts: []string{"a,b"}, Level: &level, Name: "svc"},
+		},
+		{
+			name:  "mandatory with default",
+			input: new(conflict),
+			err:   errors.New(`env: conflicting tag options: "m" and "default" in Go struct field conflict.A`),
+		},
 	}
 
 	for _, tt := range tests {
@@ -330,3 +392,227 @@ func Test_Get(t *testing.T) {
 		})
 	}
 }
+
+func Test_DefaultList(t *testing.T) {
+	// Tags cannot refer to constants, so the type is built with the platform separator in the default.
+	typ := reflect.StructOf([]reflect.StructField{{
+		Name: "Slc",
+		Type: reflect.TypeOf([]bool(nil)),
+		Tag:  reflect.StructTag(`env:"ENV_SLC,default=` + testEnvSlc + `"`),
+	}})
+
+	v := reflect.New(typ)
+	equal(t, nil, GetFrom(Map{}, v.Interface()))
+	equal(t, []bool{true, false, true}, v.Elem().Field(0).Interface())
+
+	v = reflect.New(typ)
+	equal(t, nil, GetFrom(Map{"ENV_SLC": "false"}, v.Interface()))
+	equal(t, []bool{false}, v.Elem().Field(0).Interface())
+}
+
+func Test_splitTag(t *testing.T) {
+	tests := []struct {
+		tag    string
+		expect []string
+	}{
+		{tag: "", expect: []string{""}},
+		{tag: "ENV_A,m", expect: []string{"ENV_A", "m"}},
+		{tag: ",default=1", expect: []string{"", "default=1"}},
+		{tag: "LIST,default='a,b',raw", expect: []string{"LIST", "default=a,b", "raw"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			equal(t, tt.expect, splitTag(tt.tag))
+		})
+	}
+}
+
+func Test_Prefix(t *testing.T) {
+	in := &prefixed{
+		DB:     nested{X: "db", simple: simple{A: "a"}},
+		Cache:  &nested{X: "cache", simple: simple{A: "a"}},
+		nested: nested{X: "emb", simple: simple{A: "a"}},
+	}
+	in.Deep.Inner.V = "deep"
+
+	os.Clearenv()
+	equal(t, nil, Set(in))
+
+	for _, v := range []env{
+		{name: "DB_ENV_X", value: "db"},
+		{name: "CACHE_ENV_X", value: "cache"},
+		{name: "DEEP_INNER_V", value: "deep"},
+		{name: "EMB_ENV_X", value: "emb"},
+		{name: "DB_ENV_A", value: "a"},
+	} {
+		equal(t, v.value, os.Getenv(v.name))
+	}
+
+	out := new(prefixed)
+	equal(t, nil, Get(out))
+	equal(t, in, out)
+	os.Clearenv()
+}
+
+func Test_AutoPrefix(t *testing.T) {
+	os.Clearenv()
+
+	for _, v := range []env{
+		{name: "DB.ENV_A", value: "db"},
+		{name: "CACHE.ENV_A", value: "cache"},
+		{name: "ENV_A", value: "plain"},
+	} {
+		equal(t, nil, os.Setenv(v.name, v.value))
+	}
+
+	out := new(autoPrefixed)
+	equal(t, nil, Get(out, AutoPrefix(".")))
+	equal(t, &autoPrefixed{
+		DB:     simple{A: "db"},
+		Cache:  &simple{A: "cache"},
+		Plain:  simple{A: "plain"},
+		simple: simple{A: "plain"},
+	}, out)
+
+	equal(t, "env: the required variable $DB_ENV_A is missing", Get(new(autoPrefixed), AutoPrefix("_")).Error())
+	os.Clearenv()
+}
+
+func Test_Maps(t *testing.T) {
+	half := 0.5
+	in := &maps{
+		Labels: map[string]string{"b": "2", "a": "1", "c": ""},
+		Limits: map[int]*float64{10: &half, 2: &half, -1: &half},
+	}
+
+	os.Clearenv()
+	equal(t, nil, Set(in))
+	equal(t, "a=1,b=2,c=", os.Getenv("LABELS"))
+	equal(t, "-1=0.5,2=0.5,10=0.5", os.Getenv("LIMITS"))
+
+	out := new(maps)
+	equal(t, nil, Get(out))
+	equal(t, in, out)
+
+	os.Clearenv()
+	equal(t, nil, Set(in, MapSeparators(";", ":")))
+	equal(t, "a:1;b:2;c:", os.Getenv("LABELS"))
+
+	out = new(maps)
+	equal(t, nil, Get(out, MapSeparators(";", ":")))
+	equal(t, in, out)
+
+	os.Clearenv()
+	equal(t, nil, os.Setenv("LIMITS", "1=0.5,2"))
+	equal(t, `env: cannot get data into Go struct field maps.LIMITS of type map[int]*float64: missing key/value separator in "2"`, Get(new(maps)).Error())
+	os.Clearenv()
+}
+
+func Test_AllErrors(t *testing.T) {
+	os.Clearenv()
+
+	for _, v := range []env{
+		{name: "ENV_B", value: "?"},
+		{name: "ENV_C", value: "28"},
+		{name: "D", value: "x"},
+		{name: "ENV_X", value: "value"},
+		{name: "ENV_Z", value: "1.5"},
+	} {
+		equal(t, nil, os.Setenv(v.name, v.value))
+	}
+
+	out := new(nested)
+	err := Get(out, AllErrors())
+
+	var errs Errors
+	equal(t, true, errors.As(err, &errs))
+	equal(t, 4, len(errs))
+	equal(t, "env: cannot get data into Go struct field nested.ENV_Z of type int: invalid syntax\n"+
+		"env: the required variable $ENV_A is missing\n"+
+		"env: cannot get data into Go struct field simple.ENV_B of type bool: invalid syntax\n"+
+		"env: cannot get data into Go struct field simple.D of type float64: invalid syntax", err.Error())
+
+	equal(t, true, errors.Is(err, ErrMissing))
+
+	var fe *FieldError
+	equal(t, true, errors.As(err, &fe))
+	equal(t, "nested", fe.Struct)
+	equal(t, "Z", fe.Field)
+	equal(t, "ENV_Z", fe.Name)
+
+	equal(t, "value", out.X)
+	equal(t, 28, out.C)
+
+	err = Get(new(nested))
+	equal(t, true, errors.As(err, &fe))
+	equal(t, "ENV_Z", fe.Name)
+	equal(t, false, errors.As(err, &errs))
+
+	os.Clearenv()
+}
+
+func Test_Presence(t *testing.T) {
+	empty, one := "", 1
+
+	tests := []struct {
+		name   string
+		envs   []env
+		expect *presence
+		err    error
+	}{
+		{
+			name:   "missing",
+			envs:   []env{{name: "ENV_A", value: ""}},
+			expect: &presence{D: &one},
+		},
+		{
+			name: "empty",
+			envs: []env{
+				{name: "ENV_A", value: ""},
+				{name: "ENV_C", value: ""},
+				{name: "ENV_D", value: ""},
+			},
+			expect: &presence{C: &empty, D: new(int)},
+		},
+		{
+			name: "empty notempty",
+			envs: []env{
+				{name: "ENV_A", value: ""},
+				{name: "ENV_B", value: ""},
+			},
+			err: errors.New("env: cannot get data into Go struct field presence.ENV_B of type string: the variable is empty"),
+		},
+		{
+			name: "missing mandatory",
+			err:  errors.New("env: the required variable $ENV_A is missing"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+
+			for _, v := range tt.envs {
+				equal(t, nil, os.Setenv(v.name, v.value))
+			}
+
+			out := new(presence)
+			err := Get(out)
+			if tt.err != nil {
+				equal(t, tt.err.Error(), err.Error())
+				return
+			}
+			equal(t, nil, err)
+			equal(t, tt.expect, out)
+		})
+	}
+
+	os.Clearenv()
+	equal(t, nil, Set(&presence{A: "a", C: &empty}))
+	_, ok := os.LookupEnv("ENV_C")
+	equal(t, true, ok)
+	_, ok = os.LookupEnv("ENV_D")
+	equal(t, false, ok)
+	os.Clearenv()
+}
@@ -0,0 +1,130 @@
+package envio
+
+import "bytes"
+
+// EscapeMode selects how separators inside the elements of lists and maps are escaped,
+// so that values written by Set are read back unchanged by Get.
+type EscapeMode int
+
+const (
+	// EscapeNone writes elements as is, a separator inside an element splits it when read.
+	EscapeNone EscapeMode = iota
+	// EscapeBackslash precedes separators and backslashes inside elements with a backslash: a\:b:c.
+	EscapeBackslash
+	// EscapeQuote wraps elements containing separators or double quotes in double quotes,
+	// doubling the double quotes inside, as in CSV: "a:b":c.
+	EscapeQuote
+)
+
+// Escape sets the escaping of separators inside the elements of lists and maps, EscapeNone by default.
+func Escape(mode EscapeMode) Option {
+	return func(e *engine) {
+		e.escaping = mode
+	}
+}
+
+// escape appends the element to buf, escaped so that none of the separators splits it.
+func (m EscapeMode) escape(buf, p []byte, seps ...[]byte) []byte {
+	switch m {
+	case EscapeBackslash:
+		for i := 0; i < len(p); i++ {
+			if p[i] == '\\' || separatorStart(p[i], seps) {
+				buf = append(buf, '\\')
+			}
+			buf = append(buf, p[i])
+		}
+		return buf
+	case EscapeQuote:
+		// An empty element is quoted, so that it is not taken for a missing one.
+		quote := len(p) == 0 || bytes.IndexByte(p, '"') >= 0
+		for i := 0; i < len(p) && !quote; i++ {
+			quote = separatorStart(p[i], seps)
+		}
+		if !quote {
+			return append(buf, p...)
+		}
+		buf = append(buf, '"')
+		for _, c := range p {
+			if c == '"' {
+				buf = append(buf, '"')
+			}
+			buf = append(buf, c)
+		}
+		return append(buf, '"')
+	default:
+		return append(buf, p...)
+	}
+}
+
+// split splits p at the separators that are not escaped, the elements are left escaped.
+func (m EscapeMode) split(p, sep []byte) [][]byte {
+	if m == EscapeNone || len(sep) == 0 {
+		return bytes.Split(p, sep)
+	}
+
+	var (
+		bs     [][]byte
+		start  int
+		quoted bool
+	)
+
+	for i := 0; i < len(p); i++ {
+		switch {
+		case m == EscapeBackslash && p[i] == '\\':
+			i++
+		case m == EscapeQuote && p[i] == '"':
+			quoted = !quoted
+		case !quoted && bytes.HasPrefix(p[i:], sep):
+			bs = append(bs, p[start:i])
+			start = i + len(sep)
+			i = start - 1
+		}
+	}
+
+	return append(bs, p[start:])
+}
+
+// cut slices p around the first separator that is not escaped.
+func (m EscapeMode) cut(p, sep []byte) (before, after []byte, found bool) {
+	if bs := m.split(p, sep); len(bs) > 1 {
+		return bs[0], p[len(bs[0])+len(sep):], true
+	}
+	return p, nil, false
+}
+
+// unescape returns the element without the escaping.
+func (m EscapeMode) unescape(p []byte) []byte {
+	switch m {
+	case EscapeBackslash:
+		if bytes.IndexByte(p, '\\') < 0 {
+			return p
+		}
+		buf := make([]byte, 0, len(p))
+		for i := 0; i < len(p); i++ {
+			if p[i] == '\\' && i+1 < len(p) {
+				i++
+			}
+			buf = append(buf, p[i])
+		}
+		return buf
+	case EscapeQuote:
+		if len(p) < 2 || p[0] != '"' || p[len(p)-1] != '"' {
+			return p
+		}
+		return bytes.ReplaceAll(p[1:len(p)-1], []byte(`""`), []byte(`"`))
+	default:
+		return p
+	}
+}
+
+// separatorStart reports whether c is the first byte of one of the separators.
+// Escaping every such byte, rather than only whole separators, keeps the rest of an element
+// from forming a separator together with the following one.
+func separatorStart(c byte, seps [][]byte) bool {
+	for _, sep := range seps {
+		if len(sep) != 0 && sep[0] == c {
+			return true
+		}
+	}
+	return false
+}
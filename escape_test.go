@@ -0,0 +1,226 @@
+package envio
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_Escape(t *testing.T) {
+	type escaped struct {
+		List   []string            `env:"LIST"`
+		Nested [][]string          `env:"NESTED"`
+		Pairs  map[string][]string `env:"PAIRS"`
+	}
+
+	in := &escaped{
+		List:   []string{`a:b`, `c\d`, `"e"`},
+		Nested: [][]string{{"a,b", "c"}, {"d:e"}},
+		Pairs:  map[string][]string{"k=1": {"x,y", "z"}},
+	}
+
+	// The separator is set explicitly, since the default one depends on the platform.
+	sep := Separator(":")
+
+	tests := []struct {
+		mode EscapeMode
+		exp  Map
+	}{
+		{
+			mode: EscapeBackslash,
+			exp: Map{
+				"LIST":   `a\:b:c\\d:"e"`,
+				"NESTED": `a\\,b,c:d\:e`,
+				"PAIRS":  `k\=1=x\\\,y\,z`,
+			},
+		},
+		{
+			mode: EscapeQuote,
+			exp: Map{
+				"LIST":   `"a:b":c\d:"""e"""`,
+				"NESTED": `"""a,b"",c":"d:e"`,
+				"PAIRS":  `"k=1"="""x,y"",z"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		src := Map{}
+		equal(t, nil, SetTo(src, in, sep, Escape(test.mode)))
+		equal(t, test.exp, src)
+
+		out := new(escaped)
+		equal(t, nil, GetFrom(src, out, sep, Escape(test.mode)))
+		equal(t, in, out)
+	}
+
+	// Without escaping, separators inside elements would split them.
+	err := SetTo(Map{}, &escaped{List: in.List}, sep)
+	equal(t, `env: cannot set data from Go struct field escaped.LIST of type []string: element "a:b" contains the separator ":", set another separator or use the Escape option`, err.Error())
+	out := new(escaped)
+	equal(t, nil, GetFrom(Map{"LIST": "a:b"}, out, sep))
+	equal(t, []string{"a", "b"}, out.List)
+}
+
+func Test_EscapeMultiByteSeparator(t *testing.T) {
+	type list struct {
+		List []string `env:"LIST,sep=::"`
+	}
+
+	for _, mode := range []EscapeMode{EscapeBackslash, EscapeQuote} {
+		in := &list{List: []string{"a:", ":b", "c::d"}}
+		src := Map{}
+		equal(t, nil, SetTo(src, in, Escape(mode)))
+
+		out := new(list)
+		equal(t, nil, GetFrom(src, out, Escape(mode)))
+		equal(t, in, out)
+	}
+}
+
+// roundTrip covers every kind supported by the getter and the setter.
+// Lists have at least two elements, since a list of a single empty element is written as an empty variable,
+// which is read back as an empty list. Empty elements are read back as zero values, so pointers in lists
+// point to numbers, which are never formatted empty.
+type roundTrip struct {
+	Bool     bool                `env:"BOOL"`
+	Int      int                 `env:"INT"`
+	Int8     int8                `env:"INT8"`
+	Uint     uint                `env:"UINT"`
+	Uint16   uint16              `env:"UINT16"`
+	Float32  float32             `env:"FLOAT32"`
+	Float64  float64             `env:"FLOAT64"`
+	String   string              `env:"STRING"`
+	Pointer  *string             `env:"POINTER"`
+	Bytes    []byte              `env:"BYTES,raw"`
+	Duration time.Duration       `env:"DURATION"`
+	Time     time.Time           `env:"TIME"`
+	Strings  []string            `env:"STRINGS"`
+	Array    [2]string           `env:"ARRAY"`
+	Ints     []int               `env:"INTS"`
+	Pointers []*int              `env:"POINTERS"`
+	Nested   [][]string          `env:"NESTED"`
+	Map      map[string]string   `env:"MAP"`
+	Lists    map[string][]string `env:"LISTS"`
+	Inner    struct {
+		Strings []string `env:"STRINGS,sep=';',innersep=';'"`
+	} `env:",prefix=INNER_"`
+}
+
+// emptyElements holds lists and maps with empty elements and nil pointers,
+// which are only told apart with EscapeQuote.
+type emptyElements struct {
+	Single   []string           `env:"SINGLE"`
+	Nested   [][]string         `env:"NESTED"`
+	Pointers []*string          `env:"POINTERS"`
+	Map      map[string]*string `env:"MAP"`
+}
+
+func FuzzEscape(f *testing.F) {
+	f.Add(true, int64(-1), 1.5, "a", "b", "c")
+	f.Add(false, int64(math.MaxInt64), math.Inf(-1), `a\:b`, `"c"`, "d=e,f")
+	f.Add(false, int64(0), 0.0, "", `\`, `""`)
+	f.Add(true, int64(math.MinInt64), -0.0, "a::b", ":", "::")
+	f.Add(false, int64(1), 1e-300, `"`, `\\,"`, "x;y=")
+
+	options := [][]Option{
+		{Escape(EscapeBackslash)},
+		{Escape(EscapeQuote)},
+		{Escape(EscapeBackslash), Separator("::"), InnerSeparator(",,"), MapSeparators(";;", "=>")},
+		{Escape(EscapeQuote), Separator("::"), InnerSeparator(",,"), MapSeparators(";;", "=>")},
+	}
+
+	f.Fuzz(func(t *testing.T, b bool, i int64, fl float64, x, y, z string) {
+		if math.IsNaN(fl) {
+			fl = 0
+		}
+		n := int(i)
+
+		in := &roundTrip{
+			Bool:     b,
+			Int:      int(i),
+			Int8:     int8(i),
+			Uint:     uint(i),
+			Uint16:   uint16(i),
+			Float32:  float32(fl),
+			Float64:  fl,
+			String:   x,
+			Pointer:  &y,
+			Bytes:    []byte(z),
+			Duration: time.Duration(i),
+			Time:     time.Unix(int64(int32(i)), i%int64(time.Second)).UTC(),
+			Strings:  []string{x, y, z},
+			Array:    [2]string{y, z},
+			Ints:     []int{int(i), 0},
+			Pointers: []*int{&n, nil},
+			Nested:   [][]string{{x, y}, {z, x}},
+			Map:      map[string]string{x: y, z: x},
+			Lists:    map[string][]string{y: {z, x}},
+		}
+		in.Inner.Strings = []string{z, y}
+		if len(in.Bytes) == 0 {
+			in.Bytes = nil
+		}
+		if in.Time.Nanosecond() < 0 {
+			in.Time = time.Unix(in.Time.Unix(), 0).UTC()
+		}
+
+		empty := &emptyElements{
+			Single:   []string{x},
+			Nested:   [][]string{{y}, {z}},
+			Pointers: []*string{&x, nil, &z},
+			Map:      map[string]*string{x: nil, y: &z},
+		}
+
+		for i, opts := range options {
+			src := Map{}
+			if err := SetTo(src, in, opts...); err != nil {
+				t.Fatal(err)
+			}
+
+			out := new(roundTrip)
+			if err := GetFrom(src, out, opts...); err != nil {
+				t.Fatalf("%v: %v", src, err)
+			}
+			equal(t, in, out)
+
+			if i%2 == 0 {
+				continue
+			}
+
+			src = Map{}
+			if err := SetTo(src, empty, opts...); err != nil {
+				t.Fatal(err)
+			}
+
+			outEmpty := new(emptyElements)
+			if err := GetFrom(src, outEmpty, opts...); err != nil {
+				t.Fatalf("%v: %v", src, err)
+			}
+			equal(t, empty, outEmpty)
+		}
+	})
+}
+
+func Test_EscapeEmptyElements(t *testing.T) {
+	type empty struct {
+		Single []string   `env:"SINGLE"`
+		Nested [][]string `env:"NESTED,sep=','"`
+		Ints   []*int     `env:"INTS,sep=','"`
+	}
+
+	zero := 0
+	in := &empty{
+		Single: []string{""},
+		Nested: [][]string{{"a"}, {""}},
+		Ints:   []*int{nil, &zero},
+	}
+
+	src := Map{}
+	equal(t, nil, SetTo(src, in, Escape(EscapeQuote)))
+	equal(t, Map{"SINGLE": `""`, "NESTED": `a,""""""`, "INTS": ",0"}, src)
+
+	out := new(empty)
+	equal(t, nil, GetFrom(src, out, Escape(EscapeQuote)))
+	equal(t, in, out)
+}
@@ -0,0 +1,144 @@
+package envio
+
+import (
+	"fmt"
+	"strings"
+)
+
+// expander expands references to variables in values.
+type expander struct {
+	lookup func(string) (string, bool, error)
+	stack  []string
+}
+
+// expand replaces $VAR and ${VAR} with the value of the variable, which is expanded as well,
+// ${VAR:-default} with the default if the variable is unset or empty,
+// ${VAR:?message} with an error if the variable is unset or empty, and $$ with $.
+func (x *expander) expand(str string) (string, error) {
+	if strings.IndexByte(str, '$') < 0 {
+		return str, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(str); i++ {
+		if str[i] != '$' || i+1 == len(str) {
+			b.WriteByte(str[i])
+			continue
+		}
+
+		switch c := str[i+1]; {
+		case c == '$':
+			b.WriteByte('$')
+			i++
+		case c == '{':
+			end := closingBrace(str, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("missing closing brace in %q", str[i:])
+			}
+			r, err := x.braced(str[i+2 : end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(r)
+			i = end
+		case isNameStart(c):
+			j := i + 2
+			for j < len(str) && isNameChar(str[j]) {
+				j++
+			}
+			r, _, err := x.variable(str[i+1 : j])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(r)
+			i = j - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+// braced expands the contents of ${...}.
+func (x *expander) braced(expr string) (string, error) {
+	n := 0
+	for n < len(expr) && isNameChar(expr[n]) {
+		n++
+	}
+	key, op := expr[:n], expr[n:]
+	if key == "" || !isNameStart(key[0]) {
+		return "", fmt.Errorf("invalid variable name in ${%s}", expr)
+	}
+
+	value, ok, err := x.variable(key)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case op == "":
+		return value, nil
+	case strings.HasPrefix(op, ":-"):
+		if ok && value != "" {
+			return value, nil
+		}
+		return x.expand(op[2:])
+	case strings.HasPrefix(op, ":?"):
+		if ok && value != "" {
+			return value, nil
+		}
+		msg, err := x.expand(op[2:])
+		if err != nil {
+			return "", err
+		}
+		if msg == "" {
+			msg = "parameter null or not set"
+		}
+		return "", fmt.Errorf("$%s: %s", key, msg)
+	default:
+		return "", fmt.Errorf("unsupported expression ${%s}", expr)
+	}
+}
+
+// variable returns the expanded value of the variable and whether it is present.
+func (x *expander) variable(key string) (string, bool, error) {
+	for i, k := range x.stack {
+		if k == key {
+			return "", false, fmt.Errorf("%w: $%s", ErrCycle, strings.Join(append(x.stack[i:], key), " -> $"))
+		}
+	}
+
+	value, ok, err := x.lookup(key)
+	if err != nil || !ok {
+		return "", false, err
+	}
+
+	x.stack = append(x.stack, key)
+	value, err = x.expand(value)
+	x.stack = x.stack[:len(x.stack)-1]
+	return value, true, err
+}
+
+// closingBrace returns the index of the brace closing the one before i, or -1.
+func closingBrace(str string, i int) int {
+	depth := 1
+	for ; i < len(str); i++ {
+		switch str[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isNameStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || '0' <= c && c <= '9'
+}
@@ -0,0 +1,94 @@
+package envio
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func Test_expand(t *testing.T) {
+	src := Map{
+		"USER":  "admin",
+		"HOST":  "db",
+		"EMPTY": "",
+		"URL":   "postgres://${USER}@$HOST:${PORT:-5432}/app",
+		"A":     "$B",
+		"B":     "${C}",
+		"C":     "$A",
+		"SELF":  "x$SELF",
+	}
+
+	tests := []struct {
+		input  string
+		expect string
+		err    error
+	}{
+		{input: "plain", expect: "plain"},
+		{input: "$USER@$HOST", expect: "admin@db"},
+		{input: "${USER}_1", expect: "admin_1"},
+		{input: "$URL", expect: "postgres://admin@db:5432/app"},
+		{input: "${MISSING}|$MISSING", expect: "|"},
+		{input: "${EMPTY:-default}", expect: "default"},
+		{input: "${MISSING:-${USER:-x}}", expect: "admin"},
+		{input: "${HOST:?no host}", expect: "db"},
+		{input: "$$USER costs $5 $", expect: "$USER costs $5 $"},
+		{input: "${MISSING:?host is required}", err: errors.New("$MISSING: host is required")},
+		{input: "${EMPTY:?}", err: errors.New("$EMPTY: parameter null or not set")},
+		{input: "${USER", err: errors.New(`missing closing brace in "${USER"`)},
+		{input: "${1A}", err: errors.New("invalid variable name in ${1A}")},
+		{input: "${USER/x}", err: errors.New("unsupported expression ${USER/x}")},
+		{input: "$A", err: errors.New("reference cycle: $A -> $B -> $C -> $A")},
+		{input: "$SELF", err: errors.New("reference cycle: $SELF -> $SELF")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			x := &expander{lookup: func(key string) (string, bool, error) {
+				v, ok := src.Lookup(key)
+				return v, ok, nil
+			}}
+
+			r, err := x.expand(tt.input)
+			if tt.err != nil {
+				equal(t, tt.err.Error(), err.Error())
+				return
+			}
+			equal(t, nil, err)
+			equal(t, tt.expect, r)
+		})
+	}
+}
+
+func Test_Expand(t *testing.T) {
+	type config struct {
+		URL  string `env:"URL,expand"`
+		Raw  string `env:"RAW"`
+		Port int    `env:"PORT,default=${DEFAULT_PORT},expand"`
+	}
+
+	src := Map{
+		"DB_HOST":      "db",
+		"DEFAULT_PORT": "5432",
+		"URL":          "postgres://${DB_HOST}:${PORT:-1}",
+		"RAW":          "$DB_HOST",
+	}
+
+	out := new(config)
+	equal(t, nil, GetFrom(src, out))
+	equal(t, &config{URL: "postgres://db:1", Raw: "$DB_HOST", Port: 5432}, out)
+
+	out = new(config)
+	equal(t, nil, GetFrom(src, out, Expand()))
+	equal(t, &config{URL: "postgres://db:1", Raw: "db", Port: 5432}, out)
+
+	src["URL"] = "$URL"
+	err := GetFrom(src, new(config))
+	equal(t, "env: cannot get data into Go struct field config.URL of type string: reference cycle: $URL -> $URL", err.Error())
+	equal(t, true, errors.Is(err, ErrCycle))
+
+	os.Clearenv()
+	equal(t, nil, os.Setenv("URL", "${DB_HOST:?}"))
+	err = Get(new(config))
+	equal(t, "env: cannot get data into Go struct field config.URL of type string: $DB_HOST: parameter null or not set", err.Error())
+	os.Clearenv()
+}
@@ -0,0 +1,40 @@
+package envio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// fileSuffix is appended to the name of a variable to get the name of the variable
+// that holds the path to the file with its value.
+const fileSuffix = "_FILE"
+
+// defaultMaxFileSize is the default limit of the size of files that values are read from.
+const defaultMaxFileSize = 1 << 20
+
+// readFile returns the contents of the file at the path held by the variable named by the key.
+func (s *getterState) readFile(key, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("$%s: %w", key, err)
+	}
+	defer f.Close()
+
+	p, err := io.ReadAll(io.LimitReader(f, s.maxFileSize+1))
+	if err != nil {
+		return "", fmt.Errorf("$%s: %w", key, err)
+	}
+	if int64(len(p)) > s.maxFileSize {
+		return "", fmt.Errorf("$%s: %s: %w: the limit is %d bytes", key, path, ErrFileTooLarge, s.maxFileSize)
+	}
+
+	str := string(p)
+	if s.trimNewline {
+		if str = strings.TrimSuffix(str, "\n"); len(str) != len(p) {
+			str = strings.TrimSuffix(str, "\r")
+		}
+	}
+	return str, nil
+}
@@ -0,0 +1,91 @@
+package envio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_Files(t *testing.T) {
+	type config struct {
+		Password string `env:"DB_PASSWORD,m"`
+		Token    string `env:"TOKEN,file"`
+		Key      []byte `env:"KEY,file,raw"`
+		Cert     string `env:"CERT,file,default=${DIR}/cert,expand"`
+	}
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		equal(t, nil, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	password := write("db", "secret\n")
+	token := write("token", "abc\r\n")
+	key := write("key", "\x00\x01")
+	write("cert", "CERT")
+	large := write("large", strings.Repeat("x", 11))
+
+	tests := []struct {
+		name   string
+		src    Map
+		opts   []Option
+		expect *config
+		err    error
+	}{
+		{
+			name:   "fallback",
+			src:    Map{"DB_PASSWORD_FILE": password, "TOKEN": token, "KEY": key, "DIR": dir},
+			opts:   []Option{FileFallback()},
+			expect: &config{Password: "secret\n", Token: "abc\r\n", Key: []byte{0, 1}, Cert: "CERT"},
+		},
+		{
+			name:   "trim newline",
+			src:    Map{"DB_PASSWORD_FILE": password, "TOKEN": token, "DIR": dir},
+			opts:   []Option{FileFallback(), TrimFileNewline()},
+			expect: &config{Password: "secret", Token: "abc", Cert: "CERT"},
+		},
+		{
+			name:   "variable takes precedence",
+			src:    Map{"DB_PASSWORD": "plain", "DB_PASSWORD_FILE": password, "DIR": dir},
+			opts:   []Option{FileFallback()},
+			expect: &config{Password: "plain", Cert: "CERT"},
+		},
+		{
+			name: "fallback is opt-in",
+			src:  Map{"DB_PASSWORD_FILE": password},
+			err:  errors.New("env: the required variable $DB_PASSWORD is missing"),
+		},
+		{
+			name: "missing file",
+			src:  Map{"DB_PASSWORD_FILE": filepath.Join(dir, "missing")},
+			opts: []Option{FileFallback()},
+			err:  errors.New("env: cannot get data into Go struct field config.DB_PASSWORD of type string: $DB_PASSWORD_FILE: open " + filepath.Join(dir, "missing") + ": no such file or directory"),
+		},
+		{
+			name: "too large",
+			src:  Map{"DB_PASSWORD": "plain", "TOKEN": large},
+			opts: []Option{MaxFileSize(10)},
+			err:  errors.New("env: cannot get data into Go struct field config.TOKEN of type string: $TOKEN: " + large + ": file is too large: the limit is 10 bytes"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := new(config)
+			err := GetFrom(tt.src, out, tt.opts...)
+			if tt.err != nil {
+				equal(t, tt.err.Error(), err.Error())
+				return
+			}
+			equal(t, nil, err)
+			equal(t, tt.expect, out)
+		})
+	}
+
+	err := GetFrom(Map{"DB_PASSWORD": "plain", "TOKEN": large}, new(config), MaxFileSize(10))
+	equal(t, true, errors.Is(err, ErrFileTooLarge))
+}
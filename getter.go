@@ -2,9 +2,10 @@ package envio
 
 import (
 	"bytes"
+	"context"
+	"encoding"
 	"errors"
 	"fmt"
-	"os"
 	"reflect"
 	"slices"
 	"strconv"
@@ -13,12 +14,12 @@ import (
 
 const getError = "get data into"
 
-func (e *engine) get(v any) error {
+func (e *engine) get(ctx context.Context, v any) error {
 	if t := reflect.ValueOf(v).Kind(); t != reflect.Pointer {
 		return fmt.Errorf("%s: the input value is not a pointer", name)
 	}
 
-	s := e.newGetState()
+	s := e.newGetState(ctx)
 	defer getStatePool.Put(s)
 
 	s.get(v)
@@ -27,22 +28,32 @@ func (e *engine) get(v any) error {
 
 type getterState struct {
 	*engine
-	context
+	fieldContext
 	*bytes.Buffer
+	ctx  context.Context
+	errs Errors
+	// present reports whether the last variable looked up is present, even if it is empty.
+	present bool
 }
 
 var getStatePool sync.Pool
 
-func (e *engine) newGetState() *getterState {
+func (e *engine) newGetState(ctx context.Context) *getterState {
 	if p := getStatePool.Get(); p != nil {
 		s := p.(*getterState)
-		s.err = nil
+		s.engine = e
+		s.ctx = ctx
+		s.errs = nil
+		s.present = false
+		s.reset()
+		s.fieldContext.naming = e.naming
 		s.Reset()
 		return s
 	}
 
-	s := &getterState{engine: e, Buffer: new(bytes.Buffer)}
-	s.field = new(field)
+	s := &getterState{engine: e, Buffer: new(bytes.Buffer), ctx: ctx}
+	s.reset()
+	s.fieldContext.naming = e.naming
 	return s
 }
 
@@ -51,20 +62,84 @@ func (s *getterState) get(v any) {
 		if !errors.Is(err, errExist) {
 			s.setError(name, getError, err)
 		}
+		return
 	}
+	if len(s.errs) != 0 {
+		s.err = s.errs
+	}
+}
+
+// collect adds the error of the current field to the list of errors.
+func (s *getterState) collect(err error) {
+	if !errors.Is(err, errExist) {
+		s.setError(name, getError, err)
+	}
+	s.errs = append(s.errs, s.err)
+	s.err = nil
 }
 
 func (s *getterState) reflectValue(v reflect.Value) error {
-	s.context.field.typ = v.Type()
-	return s.cachedFunctions(s.context.field.typ).getterFunc(s, v)
+	s.typ = v.Type()
+	return s.cachedFunctions(s.typ).getterFunc(s, v)
+}
+
+// lookup returns the value of the variable from the source.
+func (s *getterState) lookup(key string) (string, bool, error) {
+	str, ok, err := lookup(s.ctx, s.source, key)
+	if !ok && err == nil && s.ignoreCase {
+		return s.lookupFold(key)
+	}
+	return str, ok, err
+}
+
+func lookup(ctx context.Context, src Source, key string) (string, bool, error) {
+	if src, ok := src.(ContextSource); ok {
+		return src.LookupContext(ctx, key)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+	str, ok := src.Lookup(key)
+	return str, ok, nil
 }
 
 func (s *getterState) getEnv() error {
-	str := os.Getenv(s.field.name)
-	if s.field.mandatory && str == "" {
-		s.err = fmt.Errorf("%s: the required variable $%s is missing", name, s.field.name)
+	key := s.varName()
+	str, ok, err := s.lookup(key)
+	if err != nil {
+		return err
+	}
+	file := s.field.file
+	if !ok && s.fileFallback {
+		if str, ok, err = s.lookup(key + fileSuffix); err != nil {
+			return err
+		}
+		if ok {
+			key, file = key+fileSuffix, true
+		}
+	}
+	if !ok && s.field.hasDef {
+		str, ok = s.field.def, true
+	}
+	if s.field.mandatory && !ok {
+		s.setError(name, getError, ErrMissing)
 		return errExist
 	}
+	if ok && (s.expand || s.field.expand) {
+		x := &expander{lookup: s.lookup, stack: []string{s.varName()}}
+		if str, err = x.expand(str); err != nil {
+			return err
+		}
+	}
+	if ok && file {
+		if str, err = s.readFile(key, str); err != nil {
+			return err
+		}
+	}
+	if s.field.notEmpty && ok && str == "" {
+		return ErrEmpty
+	}
+	s.present = ok
 	s.WriteString(str)
 	return nil
 }
@@ -72,76 +147,151 @@ func (s *getterState) getEnv() error {
 type getterFunc func(*getterState, reflect.Value) error
 
 func (f *structFields) get(s *getterState, v reflect.Value) (err error) {
+	structName, field, typ := s.structName, s.field, s.typ
 	s.structName = v.Type().Name()
 
-	for _, s.field = range *f {
+	for _, fd := range *f {
+		s.field, s.typ = fd, fd.typ
 		s.Reset()
+		s.present = false
 		rv := v.Field(s.field.index)
 
-		if s.field.embedded != nil {
+		prefix := s.prefix
+		s.prefix += s.fieldPrefix(s.field)
+
+		switch {
+		case s.field.err != nil:
+			s.err = s.field.err
+			err = errExist
+		case s.field.embedded != nil:
 			if rv.Kind() == reflect.Pointer {
 				if rv.IsNil() {
 					s.err = fmt.Errorf("%s: %w: %s", name, ErrPointerToUnexported, rv.Type().Elem())
-					return errExist
+					err = errExist
+					break
 				}
 				rv = rv.Elem()
 			}
 
-			if err = s.field.embedded.get(s, rv); err != nil {
-				return
+			err = s.field.embedded.get(s, rv)
+		default:
+			if err = s.field.functions.getterFunc(s, rv); err == nil && len(fd.rules) != 0 {
+				// The getter of a nested struct leaves the context at its last field.
+				s.field = fd
+				err = s.validate(rv)
 			}
-			continue
 		}
 
-		if err = s.field.functions.getterFunc(s, rv); err != nil {
-			return
+		if err != nil {
+			if !s.allErrors {
+				return
+			}
+			s.collect(err)
 		}
+
+		s.prefix = prefix
+		s.structName = v.Type().Name()
 	}
 
-	return
+	s.structName, s.field, s.typ = structName, field, typ
+	return nil
 }
 
-func boolProc(s string, v reflect.Value) error {
-	r, err := strconv.ParseBool(s)
+// getProcFunc parses the string into the value.
+type getProcFunc func(*getterState, string, reflect.Value) error
+
+func boolProc(_ *getterState, str string, v reflect.Value) error {
+	r, err := strconv.ParseBool(str)
 	v.SetBool(r)
 	return err
 }
 
-func intProc(s string, v reflect.Value) error {
-	r, err := strconv.ParseInt(s, 10, bitSize(v.Kind()))
+func intProc(s *getterState, str string, v reflect.Value) error {
+	r, err := strconv.ParseInt(str, s.field.parseBase(), bitSize(v.Kind()))
+	if errors.Is(err, strconv.ErrRange) {
+		return rangeError(str, bitSize(v.Kind()), "integer")
+	}
 	v.SetInt(r)
 	return err
 }
 
-func uintProc(s string, v reflect.Value) error {
-	r, err := strconv.ParseUint(s, 10, bitSize(v.Kind()))
+func uintProc(s *getterState, str string, v reflect.Value) error {
+	r, err := strconv.ParseUint(str, s.field.parseBase(), bitSize(v.Kind()))
+	if errors.Is(err, strconv.ErrRange) {
+		return rangeError(str, bitSize(v.Kind()), "unsigned integer")
+	}
 	v.SetUint(r)
 	return err
 }
 
-func floatProc(s string, v reflect.Value) error {
-	r, err := strconv.ParseFloat(s, bitSize(v.Kind()))
+func floatProc(_ *getterState, str string, v reflect.Value) error {
+	r, err := strconv.ParseFloat(str, bitSize(v.Kind()))
+	if errors.Is(err, strconv.ErrRange) {
+		return rangeError(str, bitSize(v.Kind()), "float")
+	}
 	v.SetFloat(r)
 	return err
 }
 
-func pointerProc(s string, v reflect.Value) error {
-	rv := reflect.New(v.Type().Elem())
-	parser := getProc(rv.Type())
-	if err := parser(s, rv.Elem()); err != nil {
+func complexProc(_ *getterState, str string, v reflect.Value) error {
+	r, err := strconv.ParseComplex(str, bitSize(v.Kind()))
+	if errors.Is(err, strconv.ErrRange) {
+		return rangeError(str, bitSize(v.Kind()), "complex")
+	}
+	v.SetComplex(r)
+	return err
+}
+
+func pointerProc(proc getProcFunc) getProcFunc {
+	return func(s *getterState, str string, v reflect.Value) error {
+		rv := reflect.New(v.Type().Elem())
+		if err := proc(s, str, rv.Elem()); err != nil {
+			return err
+		}
+		v.Set(rv)
+		return nil
+	}
+}
+
+func stringParser(_ *getterState, str string, v reflect.Value) error {
+	v.SetString(str)
+	return nil
+}
+
+func getterProc(_ *getterState, str string, v reflect.Value) error {
+	rv := reflect.New(v.Type())
+	if err := rv.Interface().(Getter).GetENV([]byte(str)); err != nil {
 		return err
 	}
-	v.Set(rv)
+	v.Set(rv.Elem())
 	return nil
 }
 
-func stringParser(s string, v reflect.Value) error {
-	v.SetString(s)
+func textProc(_ *getterState, str string, v reflect.Value) error {
+	rv := reflect.New(v.Type())
+	if err := rv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(str)); err != nil {
+		return err
+	}
+	v.Set(rv.Elem())
 	return nil
 }
 
-func getProc(t reflect.Type) func(string, reflect.Value) error {
-	switch t.Elem().Kind() {
+// getProc returns a parser for values of the type t.
+func getProc(t reflect.Type) getProcFunc {
+	if p, ok := typeProcs(t); ok {
+		return p.get
+	}
+
+	if pt := reflect.PointerTo(t); t.Kind() != reflect.Pointer {
+		switch {
+		case pt.Implements(getter):
+			return getterProc
+		case pt.Implements(textUnmarshaler):
+			return textProc
+		}
+	}
+
+	switch t.Kind() {
 	case reflect.Bool:
 		return boolProc
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -150,10 +300,23 @@ func getProc(t reflect.Type) func(string, reflect.Value) error {
 		return uintProc
 	case reflect.Float32, reflect.Float64:
 		return floatProc
+	case reflect.Complex64, reflect.Complex128:
+		return complexProc
 	case reflect.Pointer:
-		return pointerProc
+		if proc := getProc(t.Elem()); proc != nil {
+			return pointerProc(proc)
+		}
+		return nil
 	case reflect.String:
 		return stringParser
+	case reflect.Array, reflect.Slice:
+		// An element that is a list itself uses the inner separator.
+		if proc := getProc(t.Elem()); proc != nil {
+			return func(s *getterState, str string, v reflect.Value) error {
+				return parseList(s, []byte(str), s.fieldInnerSeparator(s.field), proc, v)
+			}
+		}
+		return nil
 	default:
 		return nil
 	}
@@ -179,6 +342,37 @@ func getGetter(s *getterState, v reflect.Value) error {
 	return nil
 }
 
+// unmarshalerGetter returns a getter for types implementing encoding.TextUnmarshaler or,
+// for fields with the raw option, encoding.BinaryUnmarshaler.
+// Values of other fields are got by the fallback getter.
+func unmarshalerGetter(t reflect.Type, fallback getterFunc) getterFunc {
+	pt := reflect.PointerTo(t)
+	text, binary := pt.Implements(textUnmarshaler), pt.Implements(binaryUnmarshaler)
+
+	return func(s *getterState, v reflect.Value) error {
+		if !text && !(s.field.raw && binary) {
+			return fallback(s, v)
+		}
+		if err := s.getEnv(); err != nil {
+			return err
+		}
+		if s.Len() == 0 {
+			return nil
+		}
+
+		rv := reflect.New(t)
+		if s.field.raw && binary {
+			if err := rv.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(slices.Clone(s.Bytes())); err != nil {
+				return err
+			}
+		} else if err := textProc(s, s.String(), rv.Elem()); err != nil {
+			return err
+		}
+		v.Set(rv.Elem())
+		return nil
+	}
+}
+
 func boolGetter(s *getterState, v reflect.Value) error {
 	if err := s.getEnv(); err != nil {
 		return err
@@ -186,7 +380,7 @@ func boolGetter(s *getterState, v reflect.Value) error {
 	if s.Len() == 0 {
 		return nil
 	}
-	return boolProc(s.String(), v)
+	return boolProc(s, s.String(), v)
 }
 
 func intGetter(s *getterState, v reflect.Value) error {
@@ -196,7 +390,7 @@ func intGetter(s *getterState, v reflect.Value) error {
 	if s.Len() == 0 {
 		return nil
 	}
-	return intProc(s.String(), v)
+	return intProc(s, s.String(), v)
 }
 
 func uintGetter(s *getterState, v reflect.Value) error {
@@ -206,7 +400,7 @@ func uintGetter(s *getterState, v reflect.Value) error {
 	if s.Len() == 0 {
 		return nil
 	}
-	return uintProc(s.String(), v)
+	return uintProc(s, s.String(), v)
 }
 
 func floatGetter(s *getterState, v reflect.Value) error {
@@ -216,11 +410,21 @@ func floatGetter(s *getterState, v reflect.Value) error {
 	if s.Len() == 0 {
 		return nil
 	}
-	return floatProc(s.String(), v)
+	return floatProc(s, s.String(), v)
+}
+
+func complexGetter(s *getterState, v reflect.Value) error {
+	if err := s.getEnv(); err != nil {
+		return err
+	}
+	if s.Len() == 0 {
+		return nil
+	}
+	return complexProc(s, s.String(), v)
 }
 
 func arrayGetter(t reflect.Type) getterFunc {
-	proc := getProc(t)
+	proc := getProc(t.Elem())
 	if proc == nil {
 		return unsupportedTypeGetter
 	}
@@ -241,15 +445,41 @@ func arrayGetter(t reflect.Type) getterFunc {
 			}
 			return nil
 		}
-		bs := bytes.Split(s.Bytes(), s.separator)
-		if len(bs) > v.Len() {
-			return errors.New("index out of range")
+		return parseList(s, s.Bytes(), s.fieldSeparator(s.field), proc, v)
+	}
+}
+
+func mapGetter(t reflect.Type) getterFunc {
+	keyProc, valueProc := getProc(t.Key()), getProc(t.Elem())
+	if keyProc == nil || valueProc == nil {
+		return unsupportedTypeGetter
+	}
+
+	return func(s *getterState, v reflect.Value) error {
+		if err := s.getEnv(); err != nil {
+			return err
 		}
-		for i, r := range bs {
-			if err := proc(string(r), v.Index(i)); err != nil {
+		if s.Len() == 0 {
+			return nil
+		}
+		pairSep, kvSep := s.fieldMapSeparators(s.field)
+		bs := s.escaping.split(s.Bytes(), pairSep)
+		m := reflect.MakeMapWithSize(t, len(bs))
+		for _, r := range bs {
+			k, val, ok := s.escaping.cut(r, kvSep)
+			if !ok {
+				return fmt.Errorf("missing key/value separator in %q", r)
+			}
+			rk, rv := reflect.New(t.Key()).Elem(), reflect.New(t.Elem()).Elem()
+			if err := parseElement(s, k, keyProc, rk); err != nil {
+				return err
+			}
+			if err := parseElement(s, val, valueProc, rv); err != nil {
 				return err
 			}
+			m.SetMapIndex(rk, rv)
 		}
+		v.Set(m)
 		return nil
 	}
 }
@@ -268,7 +498,9 @@ func pointerGetter(s *getterState, v reflect.Value) error {
 		if err := s.reflectValue(rv.Elem()); err != nil {
 			return err
 		}
-		if !isEmptyValue(rv.Elem()) {
+		// A variable that is present but empty results in a pointer to the zero value,
+		// while nested structs are allocated even if none of their variables is present.
+		if s.present || s.isNested(v.Type()) || !rv.Elem().IsZero() {
 			v.Set(rv)
 		}
 		return nil
@@ -277,7 +509,7 @@ func pointerGetter(s *getterState, v reflect.Value) error {
 }
 
 func sliceGetter(t reflect.Type) getterFunc {
-	parser := getProc(t)
+	parser := getProc(t.Elem())
 	if parser == nil {
 		return unsupportedTypeGetter
 	}
@@ -293,14 +525,7 @@ func sliceGetter(t reflect.Type) getterFunc {
 			v.SetBytes(slices.Clone(s.Bytes()))
 			return nil
 		}
-		bs := bytes.Split(s.Bytes(), s.separator)
-		v.Set(reflect.MakeSlice(t, len(bs), len(bs)))
-		for i, r := range bs {
-			if err := parser(string(r), v.Index(i)); err != nil {
-				return err
-			}
-		}
-		return nil
+		return parseList(s, s.Bytes(), s.fieldSeparator(s.field), parser, v)
 	}
 }
 
@@ -311,7 +536,20 @@ func stringGetter(s *getterState, v reflect.Value) error {
 	if s.Len() == 0 {
 		return nil
 	}
-	return stringParser(s.String(), v)
+	return stringParser(s, s.String(), v)
+}
+
+// scalarGetter returns a getter that parses the variable with the proc.
+func scalarGetter(proc getProcFunc) getterFunc {
+	return func(s *getterState, v reflect.Value) error {
+		if err := s.getEnv(); err != nil {
+			return err
+		}
+		if s.Len() == 0 {
+			return nil
+		}
+		return proc(s, s.String(), v)
+	}
 }
 
 func structGetter(s *getterState, v reflect.Value) error {
@@ -0,0 +1,404 @@
+package envio
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// countSuffix is the suffix of the variable holding the number of elements of a list of structs.
+const countSuffix = "COUNT"
+
+// indexDelimiter returns the delimiter between the name of a list of structs, the index and the names of the fields,
+// as in UPSTREAM_0_HOST: the delimiter of AutoPrefix or "_".
+func (e *engine) indexDelimiter() string {
+	if e.autoPrefix {
+		return e.delimiter
+	}
+	return "_"
+}
+
+// listPrefix returns the common prefix of the indexed variables of the list of structs of the field,
+// prefix includes the explicit prefix of the field, if any.
+func (e *engine) listPrefix(prefix string, f *field) string {
+	if f.hasPrefix {
+		return prefix
+	}
+	return prefix + f.envName(e.naming) + e.indexDelimiter()
+}
+
+// elementPrefix returns the prefix of the variables of the element of a list or a map of structs with the index or the key.
+func (e *engine) elementPrefix(listPrefix, index string) string {
+	return listPrefix + index + e.indexDelimiter()
+}
+
+// elementNames returns the names of the variables of an element of a list of structs of the type t.
+// The getters and setters of lists and maps of structs compute the names without a prefix once per call,
+// which also makes lists of a recursive type fail before any element is got/set.
+func (e *engine) elementNames(t reflect.Type, prefix string) ([]string, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var vars []Variable
+	if err := e.describeStruct(&vars, t, nil, prefix, ""); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		names[i] = v.Name
+	}
+	return names, nil
+}
+
+// indexedGetter returns a getter for arrays and slices of structs whose elements are got from indexed variables,
+// such as UPSTREAM_0_HOST and UPSTREAM_1_HOST. The number of elements is held by UPSTREAM_COUNT,
+// or is the number of contiguous indices starting at 0 that any variable is present for.
+func indexedGetter(t reflect.Type) getterFunc {
+	return func(s *getterState, v reflect.Value) error {
+		field, prefix := s.field, s.prefix
+		p := s.listPrefix(prefix, field)
+		names, err := s.elementNames(t.Elem(), "")
+		if err != nil {
+			return err
+		}
+
+		n, counted, err := s.listLen(p, names)
+		if err != nil {
+			return err
+		}
+		if n == 0 && !counted {
+			if field.mandatory {
+				s.setError(name, getError, ErrMissing)
+				return errExist
+			}
+			return nil
+		}
+
+		if v.Kind() == reflect.Array {
+			if n > v.Len() {
+				return errors.New("index out of range")
+			}
+		} else {
+			v.Set(reflect.MakeSlice(v.Type(), n, n))
+		}
+
+		for i := 0; i < n; i++ {
+			ev := v.Index(i)
+			if ev.Kind() == reflect.Pointer {
+				ev.Set(reflect.New(ev.Type().Elem()))
+				ev = ev.Elem()
+			}
+
+			s.prefix = s.elementPrefix(p, strconv.Itoa(i))
+			f := s.cachedFields(ev.Type())
+			if err = f.get(s, ev); err != nil {
+				return err
+			}
+		}
+
+		s.field, s.prefix = field, prefix
+		s.present = true
+		return nil
+	}
+}
+
+// listLen returns the number of elements of the list of structs with the prefix
+// and reports whether it is held by the count variable. names are the names of the variables of an element.
+func (s *getterState) listLen(prefix string, names []string) (int, bool, error) {
+	key := prefix + countSuffix
+	str, ok, err := s.lookup(key)
+	if err != nil {
+		return 0, false, err
+	}
+	if ok {
+		n, err := strconv.Atoi(str)
+		if err != nil || n < 0 {
+			return 0, false, fmt.Errorf("invalid number of elements %q in $%s", str, key)
+		}
+		return n, true, nil
+	}
+
+	n := 0
+	for {
+		ok, err = s.elementPresent(s.elementPrefix(prefix, strconv.Itoa(n)), names)
+		if err != nil || !ok {
+			return n, false, err
+		}
+		n++
+	}
+}
+
+// elementPresent reports whether any variable of the element of a list of structs with the prefix is present.
+func (s *getterState) elementPresent(prefix string, names []string) (bool, error) {
+	for _, name := range names {
+		key := prefix + name
+		_, ok, err := s.lookup(key)
+		if err == nil && !ok && s.fileFallback {
+			_, ok, err = s.lookup(key + fileSuffix)
+		}
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// indexedSetter returns a setter for arrays and slices of structs whose elements are set to indexed variables.
+// If the sink is also a source, the count variable is updated if it is present,
+// and the variables of the elements beyond the end of the list are removed.
+// When unsetting, only the variables that still hold the values of the elements are removed with OnlyUnchanged.
+func indexedSetter(t reflect.Type) setterFunc {
+	return func(s *setterState, v reflect.Value) error {
+		field, prefix := s.field, s.prefix
+		p := s.listPrefix(prefix, field)
+		names, err := s.elementNames(t.Elem(), "")
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			s.prefix = s.elementPrefix(p, strconv.Itoa(i))
+			ev := valueFromPtr(v.Index(i))
+			f := s.cachedFields(ev.Type())
+			if err = f.set(s, ev); err != nil {
+				return err
+			}
+		}
+
+		s.field, s.prefix = field, prefix
+		if s.source == nil || s.unsetting && s.onlyUnchanged {
+			return nil
+		}
+
+		// The old count is read before it is overwritten, since it may cover elements after a gap.
+		key := p + countSuffix
+		str, ok, err := lookup(s.ctx, s.source, key)
+		if err != nil {
+			return err
+		}
+		count, _ := strconv.Atoi(str)
+		if ok {
+			if err = s.setVar(key, strconv.AppendInt(s.scratch[:0], int64(v.Len()), 10)); err != nil {
+				return err
+			}
+		}
+
+		return s.removeStale(p, names, v.Len(), count)
+	}
+}
+
+// removeStale removes the variables of the elements of the list of structs with the prefix from the index n on.
+// If the source is a Lister, the indices of the elements are found by listing its variables,
+// otherwise the elements up to the old count and then up to the first index without variables are removed.
+func (s *setterState) removeStale(prefix string, names []string, n, count int) error {
+	if l, ok := s.source.(Lister); ok {
+		var indices []int
+		seen := make(map[int]bool)
+		for _, k := range l.Keys() {
+			rest, ok := strings.CutPrefix(k, prefix)
+			if !ok {
+				continue
+			}
+			index, _, _ := strings.Cut(rest, s.indexDelimiter())
+			i, err := strconv.Atoi(index)
+			if err != nil || i < n || seen[i] || strconv.Itoa(i) != index {
+				continue
+			}
+			indices = append(indices, i)
+			seen[i] = true
+		}
+
+		sort.Ints(indices)
+		for _, i := range indices {
+			if _, err := s.removeElement(s.elementPrefix(prefix, strconv.Itoa(i)), names); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i := n; ; i++ {
+		found, err := s.removeElement(s.elementPrefix(prefix, strconv.Itoa(i)), names)
+		if err != nil || !found && i >= count {
+			return err
+		}
+	}
+}
+
+// removeElement removes the variables of the element of a list or a map of structs with the prefix
+// and reports whether any variable was present. names are the names of the variables of an element.
+func (s *setterState) removeElement(prefix string, names []string) (bool, error) {
+	found := false
+	for _, name := range names {
+		key := prefix + name
+		_, ok, err := lookup(s.ctx, s.source, key)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			if err = s.removeVar(key); err != nil {
+				return false, err
+			}
+			found = true
+		}
+	}
+	return found, nil
+}
+
+// keyedGetter returns a getter for maps of structs whose elements are got from variables named after the keys,
+// such as REGION_EU_ENDPOINT and REGION_US_ENDPOINT. The keys are found by listing the variables of the source,
+// which must be a Lister. If a name ends with the names of several fields, the longest name wins.
+func keyedGetter(t reflect.Type) getterFunc {
+	keyProc := getProc(t.Key())
+	if keyProc == nil {
+		return unsupportedTypeGetter
+	}
+
+	return func(s *getterState, v reflect.Value) error {
+		field, prefix := s.field, s.prefix
+		p := s.listPrefix(prefix, field)
+		names, err := s.elementNames(t.Elem(), "")
+		if err != nil {
+			return err
+		}
+
+		keys, err := s.elementKeys(s.source, p, names)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			if field.mandatory {
+				s.setError(name, getError, ErrMissing)
+				return errExist
+			}
+			return nil
+		}
+
+		m := reflect.MakeMapWithSize(t, len(keys))
+		for _, k := range keys {
+			rk := reflect.New(t.Key()).Elem()
+			if err = keyProc(s, k, rk); err != nil {
+				return fmt.Errorf("invalid key %q: %w", k, unwrapErr(err))
+			}
+
+			ev := reflect.New(t.Elem()).Elem()
+			sv := ev
+			if ev.Kind() == reflect.Pointer {
+				ev.Set(reflect.New(ev.Type().Elem()))
+				sv = ev.Elem()
+			}
+
+			s.prefix = s.elementPrefix(p, k)
+			f := s.cachedFields(sv.Type())
+			if err = f.get(s, sv); err != nil {
+				return err
+			}
+			s.field, s.prefix = field, prefix
+
+			m.SetMapIndex(rk, ev)
+		}
+
+		v.Set(m)
+		s.present = true
+		return nil
+	}
+}
+
+// elementKeys returns the sorted keys of the map of structs with the prefix that any variable is present for.
+// names are the names of the variables of an element.
+func (e *engine) elementKeys(src Source, prefix string, names []string) ([]string, error) {
+	lister, ok := src.(Lister)
+	if !ok {
+		return nil, errors.New("the source cannot list variables")
+	}
+
+	delim := e.indexDelimiter()
+	seen := make(map[string]bool)
+	for _, k := range lister.Keys() {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok {
+			continue
+		}
+		if e.fileFallback {
+			rest = strings.TrimSuffix(rest, fileSuffix)
+		}
+
+		key, n := "", 0
+		for _, name := range names {
+			suffix := delim + name
+			if len(suffix) > n && len(rest) > len(suffix) && strings.HasSuffix(rest, suffix) {
+				key, n = rest[:len(rest)-len(suffix)], len(suffix)
+			}
+		}
+		if n != 0 {
+			seen[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// keyedSetter returns a setter for maps of structs whose elements are set to variables named after the keys.
+// If the sink is also a Lister, the variables of the keys that are not in the map are removed.
+func keyedSetter(t reflect.Type) setterFunc {
+	keyProc := setProc(t.Key())
+	if keyProc == nil {
+		return unsupportedTypeSetter
+	}
+
+	return func(s *setterState, v reflect.Value) error {
+		field, prefix := s.field, s.prefix
+		p := s.listPrefix(prefix, field)
+		names, err := s.elementNames(t.Elem(), "")
+		if err != nil {
+			return err
+		}
+
+		keys, err := sortedKeys(s, v, keyProc)
+		if err != nil {
+			return err
+		}
+
+		written := make(map[string]bool, len(keys))
+		for _, k := range keys {
+			key := string(k.text)
+			written[key] = true
+
+			s.prefix = s.elementPrefix(p, key)
+			ev := valueFromPtr(v.MapIndex(k.value))
+			f := s.cachedFields(ev.Type())
+			if err = f.set(s, ev); err != nil {
+				return err
+			}
+			s.field, s.prefix = field, prefix
+		}
+
+		if _, ok := s.source.(Lister); !ok || s.unsetting && s.onlyUnchanged {
+			return nil
+		}
+
+		stale, err := s.elementKeys(s.source, p, names)
+		if err != nil {
+			return err
+		}
+		for _, key := range stale {
+			if written[key] {
+				continue
+			}
+			if _, err = s.removeElement(s.elementPrefix(p, key), names); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
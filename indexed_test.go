@@ -0,0 +1,262 @@
+package envio
+
+import (
+	"errors"
+	"testing"
+)
+
+type upstream struct {
+	Host string `env:"HOST,m"`
+	Port int    `env:"PORT,default=80"`
+}
+
+type indexed struct {
+	Upstreams []upstream   `env:"UPSTREAM"`
+	Backups   [2]*upstream `env:",prefix=BACKUP_"`
+	Pools     []pool       `env:"POOL"`
+	Ptr       *[]upstream  `env:"PTR"`
+	Optional  []upstream   `env:"OPTIONAL"`
+	Required  [1]upstream  `env:"REQUIRED,m"`
+}
+
+type pool struct {
+	Name    string     `env:"NAME"`
+	Members []upstream `env:"MEMBER"`
+}
+
+func Test_Indexed(t *testing.T) {
+	src := Map{
+		"UPSTREAM_0_HOST":      "a",
+		"UPSTREAM_1_HOST":      "b",
+		"UPSTREAM_1_PORT":      "8080",
+		"UPSTREAM_3_HOST":      "not contiguous",
+		"BACKUP_COUNT":         "1",
+		"BACKUP_0_HOST":        "c",
+		"POOL_0_NAME":          "p",
+		"POOL_0_MEMBER_0_HOST": "d",
+		"PTR_0_HOST":           "e",
+		"REQUIRED_0_HOST":      "f",
+	}
+
+	out := new(indexed)
+	equal(t, nil, GetFrom(src, out))
+	equal(t, []upstream{{Host: "a", Port: 80}, {Host: "b", Port: 8080}}, out.Upstreams)
+	equal(t, [2]*upstream{{Host: "c", Port: 80}}, out.Backups)
+	equal(t, []pool{{Name: "p", Members: []upstream{{Host: "d", Port: 80}}}}, out.Pools)
+	equal(t, &[]upstream{{Host: "e", Port: 80}}, out.Ptr)
+	equal(t, []upstream(nil), out.Optional)
+
+	out.Upstreams = out.Upstreams[:1]
+	out.Backups[1] = &upstream{Host: "g", Port: 1}
+	dst := Map{"UPSTREAM_1_HOST": "stale", "UPSTREAM_2_PORT": "1", "UPSTREAM_4_HOST": "gap", "BACKUP_COUNT": "1"}
+	equal(t, nil, SetTo(dst, out))
+	equal(t, Map{
+		"UPSTREAM_0_HOST":      "a",
+		"UPSTREAM_0_PORT":      "80",
+		"BACKUP_COUNT":         "2",
+		"BACKUP_0_HOST":        "c",
+		"BACKUP_0_PORT":        "80",
+		"BACKUP_1_HOST":        "g",
+		"BACKUP_1_PORT":        "1",
+		"POOL_0_NAME":          "p",
+		"POOL_0_MEMBER_0_HOST": "d",
+		"POOL_0_MEMBER_0_PORT": "80",
+		"PTR_0_HOST":           "e",
+		"PTR_0_PORT":           "80",
+		"REQUIRED_0_HOST":      "f",
+		"REQUIRED_0_PORT":      "80",
+	}, dst)
+
+	back := new(indexed)
+	equal(t, nil, GetFrom(dst, back))
+	equal(t, out, back)
+
+	equal(t, nil, UnsetFrom(dst, out))
+	equal(t, Map{}, dst)
+
+	vars, err := Describe(out)
+	equal(t, nil, err)
+	equal(t, "UPSTREAM_<n>_HOST", vars[0].Name)
+	equal(t, "Upstreams[n].Host", vars[0].Field)
+}
+
+func Test_IndexedErrors(t *testing.T) {
+	tests := []struct {
+		src Map
+		err string
+	}{
+		{
+			src: Map{"UPSTREAM_COUNT": "2", "UPSTREAM_0_HOST": "a"},
+			err: "env: the required variable $UPSTREAM_1_HOST is missing",
+		},
+		{
+			src: Map{"UPSTREAM_COUNT": "-1"},
+			err: `env: cannot get data into Go struct field indexed.UPSTREAM of type []envio.upstream: invalid number of elements "-1" in $UPSTREAM_COUNT`,
+		},
+		{
+			src: Map{"BACKUP_2_PORT": "1", "BACKUP_1_PORT": "1", "BACKUP_0_HOST": "a"},
+			err: "env: cannot get data into Go struct field indexed.BACKUP_Backups of type [2]*envio.upstream: index out of range",
+		},
+		{
+			src: Map{},
+			err: "env: the required variable $REQUIRED is missing",
+		},
+	}
+
+	for _, test := range tests {
+		err := GetFrom(test.src, new(struct {
+			indexed
+		}))
+		equal(t, test.err, err.Error())
+	}
+
+	var fe *FieldError
+	err := GetFrom(Map{"UPSTREAM_0_HOST": "a", "UPSTREAM_0_PORT": "x", "REQUIRED_0_HOST": "f"}, new(indexed))
+	equal(t, true, errors.As(err, &fe))
+	equal(t, "UPSTREAM_0_PORT", fe.Name)
+}
+
+// store is a Source and a Sink that cannot list its variables.
+type store struct {
+	m Map
+}
+
+func (s store) Lookup(key string) (string, bool) { return s.m.Lookup(key) }
+func (s store) Set(key, value string) error      { return s.m.Set(key, value) }
+func (s store) Unset(key string) error           { return s.m.Unset(key) }
+
+func Test_IndexedStale(t *testing.T) {
+	type list struct {
+		U []upstream `env:"U"`
+	}
+
+	in := &list{U: []upstream{{Host: "a", Port: 80}}}
+	exp := Map{"U_COUNT": "1", "U_0_HOST": "a", "U_0_PORT": "80"}
+
+	// A Lister is searched for the elements after a gap.
+	dst := Map{"U_COUNT": "2", "U_0_HOST": "a", "U_1_HOST": "b", "U_3_HOST": "d", "U_10_PORT": "1", "U_X_HOST": "x"}
+	equal(t, nil, SetTo(dst, in))
+	exp["U_X_HOST"] = "x"
+	equal(t, exp, dst)
+	delete(exp, "U_X_HOST")
+
+	// Otherwise the elements are removed up to the old count, and then up to the first gap.
+	dst = Map{"U_COUNT": "4", "U_0_HOST": "a", "U_1_HOST": "b", "U_3_HOST": "d", "U_5_HOST": "f"}
+	equal(t, nil, SetTo(store{dst}, in))
+	exp["U_5_HOST"] = "f"
+	equal(t, exp, dst)
+}
+
+type node struct {
+	Name     string          `env:"NAME"`
+	Children []node          `env:"CHILD"`
+	Links    map[string]node `env:"LINK"`
+}
+
+func Test_IndexedRecursive(t *testing.T) {
+	const msg = "cannot support type: recursive type envio.node"
+
+	err := GetFrom(Map{"NAME": "x"}, &node{})
+	equal(t, true, errors.Is(err, ErrNotSupportType))
+	equal(t, "env: cannot get data into Go struct field node.CHILD of type []envio.node: "+msg, err.Error())
+
+	err = GetFrom(Map{"NAME": "x", "CHILD_COUNT": "1"}, &node{})
+	equal(t, true, errors.Is(err, ErrNotSupportType))
+
+	err = SetTo(Map{}, &node{Children: []node{{Name: "a"}}})
+	equal(t, "env: cannot set data from Go struct field node.CHILD of type []envio.node: "+msg, err.Error())
+
+	err = SetTo(Map{}, &struct {
+		Links map[string]node `env:"LINK"`
+	}{Links: map[string]node{"a": {}}})
+	equal(t, true, errors.Is(err, ErrNotSupportType))
+
+	_, err = Describe(&node{})
+	equal(t, msg, err.Error())
+}
+
+type region struct {
+	Endpoint    string `env:"ENDPOINT,m"`
+	Replicas    int    `env:"REPLICAS"`
+	APIEndpoint string `env:"API_ENDPOINT"`
+}
+
+type keyed struct {
+	Regions map[string]region  `env:",prefix=REGION_"`
+	Zones   map[int]*region    `env:"ZONE"`
+	None    map[string]*region `env:"NONE"`
+}
+
+func Test_Keyed(t *testing.T) {
+	src := Map{
+		"REGION_EU_ENDPOINT":          "eu",
+		"REGION_EU_REPLICAS":          "2",
+		"REGION_US_WEST_ENDPOINT":     "us",
+		"REGION_US_WEST_API_ENDPOINT": "api",
+		"ZONE_1_ENDPOINT":             "z1",
+		"ZONE_2_ENDPOINT":             "z2",
+		"REGION_EU":                   "unrelated",
+	}
+
+	out := new(keyed)
+	equal(t, nil, GetFrom(src, out))
+	equal(t, map[string]region{
+		"EU":      {Endpoint: "eu", Replicas: 2},
+		"US_WEST": {Endpoint: "us", APIEndpoint: "api"},
+	}, out.Regions)
+	equal(t, map[int]*region{1: {Endpoint: "z1"}, 2: {Endpoint: "z2"}}, out.Zones)
+	equal(t, map[string]*region(nil), out.None)
+
+	delete(out.Regions, "US_WEST")
+	out.Zones[3] = &region{Endpoint: "z3"}
+	equal(t, nil, SetTo(src, out))
+	equal(t, Map{
+		"REGION_EU_ENDPOINT":     "eu",
+		"REGION_EU_REPLICAS":     "2",
+		"REGION_EU_API_ENDPOINT": "",
+		"ZONE_1_ENDPOINT":        "z1",
+		"ZONE_1_REPLICAS":        "0",
+		"ZONE_1_API_ENDPOINT":    "",
+		"ZONE_2_ENDPOINT":        "z2",
+		"ZONE_2_REPLICAS":        "0",
+		"ZONE_2_API_ENDPOINT":    "",
+		"ZONE_3_ENDPOINT":        "z3",
+		"ZONE_3_REPLICAS":        "0",
+		"ZONE_3_API_ENDPOINT":    "",
+		"REGION_EU":              "unrelated",
+	}, src)
+
+	back := new(keyed)
+	equal(t, nil, GetFrom(src, back))
+	equal(t, out, back)
+
+	env := Environ{"ZONE_1_ENDPOINT=a", "ZONE_X_ENDPOINT=b"}
+	err := GetFrom(env, new(keyed))
+	equal(t, `env: cannot get data into Go struct field keyed.ZONE of type map[int]*envio.region: invalid key "X": invalid syntax`, err.Error())
+
+	err = GetFrom(Map{"ZONE_1_REPLICAS": "1"}, new(keyed))
+	equal(t, "env: the required variable $ZONE_1_ENDPOINT is missing", err.Error())
+
+	err = GetFrom(Environ(nil), new(keyed))
+	equal(t, nil, err)
+
+	err = GetFrom(blockingSource{}, new(keyed))
+	equal(t, nil, err)
+
+	vars, err := Describe(out)
+	equal(t, nil, err)
+	equal(t, "REGION_<key>_ENDPOINT", vars[0].Name)
+	equal(t, "Regions[key].Endpoint", vars[0].Field)
+}
+
+type lookupOnly map[string]string
+
+func (l lookupOnly) Lookup(key string) (string, bool) {
+	v, ok := l[key]
+	return v, ok
+}
+
+func Test_KeyedNotLister(t *testing.T) {
+	err := GetFrom(lookupOnly{}, new(keyed))
+	equal(t, "env: cannot get data into Go struct field keyed.REGION_Regions of type map[string]envio.region: the source cannot list variables", err.Error())
+}
@@ -0,0 +1,100 @@
+package envio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// fieldSeparator returns the separator between the elements of the list of the field.
+func (e *engine) fieldSeparator(f *field) []byte {
+	if f.sep != nil {
+		return f.sep
+	}
+	return e.separator
+}
+
+// fieldInnerSeparator returns the separator between the elements of the lists nested in the list of the field.
+func (e *engine) fieldInnerSeparator(f *field) []byte {
+	if f.innerSep != nil {
+		return f.innerSep
+	}
+	return e.innerSeparator
+}
+
+// fieldMapSeparators returns the separator between the pairs of the map of the field
+// and the separator between the key and the value of a pair.
+func (e *engine) fieldMapSeparators(f *field) (pair, keyValue []byte) {
+	pair, keyValue = e.pairSeparator, e.keyValueSeparator
+	if f.sep != nil {
+		pair = f.sep
+	}
+	if f.kvSep != nil {
+		keyValue = f.kvSep
+	}
+	return
+}
+
+// parseElement parses the element of a list or a map into v, without the surrounding whitespace, if requested,
+// and the escaping. An empty element leaves v with the zero value, while an element that is only empty
+// once unescaped, such as "" with EscapeQuote, is present: a pointer gets a pointer to the zero value.
+func parseElement(s *getterState, p []byte, proc getProcFunc, v reflect.Value) error {
+	if s.field.trim {
+		p = bytes.TrimSpace(p)
+	}
+	if len(p) == 0 {
+		return nil
+	}
+	if p = s.escaping.unescape(p); len(p) == 0 {
+		if v.Kind() == reflect.Pointer {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return nil
+	}
+	return proc(s, string(p), v)
+}
+
+// parseList parses the elements of the list into the array or slice v.
+func parseList(s *getterState, p, sep []byte, proc getProcFunc, v reflect.Value) error {
+	bs := s.escaping.split(p, sep)
+
+	if v.Kind() == reflect.Array {
+		if len(bs) > v.Len() {
+			return errors.New("index out of range")
+		}
+	} else {
+		v.Set(reflect.MakeSlice(v.Type(), len(bs), len(bs)))
+	}
+
+	for i, r := range bs {
+		if err := parseElement(s, r, proc, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatList appends the elements of the array or slice v to buf.
+// Nil pointers are written as empty elements.
+func formatList(s *setterState, buf, sep []byte, proc setProcFunc, v reflect.Value) ([]byte, error) {
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			buf = append(buf, sep...)
+		}
+		ev := v.Index(i)
+		if ev.Kind() == reflect.Pointer && ev.IsNil() {
+			continue
+		}
+		p, err := proc(s, ev)
+		if err != nil {
+			return nil, err
+		}
+		// Without escaping, such an element would be got back as several elements.
+		if s.escaping == EscapeNone && len(sep) != 0 && bytes.Contains(p, sep) {
+			return nil, fmt.Errorf("element %q contains the separator %q, set another separator or use the Escape option", p, sep)
+		}
+		buf = s.escaping.escape(buf, p, sep)
+	}
+	return buf, nil
+}
@@ -0,0 +1,67 @@
+package envio
+
+import (
+	"errors"
+	"testing"
+)
+
+type lists struct {
+	Hosts   []string          `env:"HOSTS,sep=','"`
+	Ports   [3]int            `env:"PORTS,sep=' ',trim"`
+	Matrix  [][]int           `env:"MATRIX,sep=;"`
+	Groups  [][2]string       `env:"GROUPS,sep=|,innersep=/"`
+	Weights map[string][]int  `env:"WEIGHTS,sep=;,kvsep=:"`
+	Empty   []*int            `env:"EMPTY,sep=','"`
+	Trimmed []string          `env:"TRIMMED,sep=',',trim"`
+	Names   map[string]string `env:"NAMES,trim"`
+}
+
+func Test_Lists(t *testing.T) {
+	two := 2
+
+	in := &lists{
+		Hosts:   []string{"a", "b", "c"},
+		Ports:   [3]int{80, 443},
+		Matrix:  [][]int{{1, 2}, {3}, {}},
+		Groups:  [][2]string{{"a", "b"}, {"c", ""}},
+		Weights: map[string][]int{"x": {1, 2}, "y": {3}},
+		Empty:   []*int{nil, &two},
+		Trimmed: []string{"a", "b"},
+		Names:   map[string]string{"k": "v"},
+	}
+
+	src := Map{}
+	equal(t, nil, SetTo(src, in))
+	equal(t, Map{
+		"HOSTS":   "a,b,c",
+		"PORTS":   "80 443 0",
+		"MATRIX":  "1,2;3;",
+		"GROUPS":  "a/b|c/",
+		"WEIGHTS": "x:1,2;y:3",
+		"EMPTY":   ",2",
+		"TRIMMED": "a,b",
+		"NAMES":   "k=v",
+	}, src)
+
+	src["PORTS"] = "80  443"
+	src["EMPTY"] = ",2"
+	src["TRIMMED"] = " a , b "
+	src["NAMES"] = " k = v "
+
+	out := new(lists)
+	equal(t, nil, GetFrom(src, out))
+	in.Ports = [3]int{80, 0, 443}
+	in.Matrix[2] = nil
+	equal(t, in, out)
+
+	out = new(lists)
+	equal(t, nil, GetFrom(Map{"HOSTS": "a:b"}, out, Separator(":")))
+	equal(t, []string{"a:b"}, out.Hosts)
+
+	out = new(lists)
+	equal(t, nil, GetFrom(Map{"MATRIX": "1 2;3"}, out, InnerSeparator(" ")))
+	equal(t, [][]int{{1, 2}, {3}}, out.Matrix)
+
+	err := GetFrom(Map{"GROUPS": "a/b/c"}, new(lists))
+	equal(t, errors.New("env: cannot get data into Go struct field lists.GROUPS of type [][2]string: index out of range").Error(), err.Error())
+}
@@ -0,0 +1,102 @@
+package envio
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Naming makes fields without a name in the tag be got/set by the name that the function derives from the Go field name,
+// such as UpperSnakeCase, KebabCase or DotCase. The names of automatic prefixes are derived as well.
+// Names in tags are used as they are.
+func Naming(fn func(goName string) string) Option {
+	return func(e *engine) {
+		e.naming = fn
+	}
+}
+
+// IgnoreCase makes Get look up a missing variable by a name that differs only in case,
+// if the source is a Lister. If several names match, the first of them in sorted order is used.
+func IgnoreCase() Option {
+	return func(e *engine) {
+		e.ignoreCase = true
+	}
+}
+
+// UpperSnakeCase converts the Go name to upper snake case, keeping acronyms together: "HTTPPort" becomes "HTTP_PORT".
+func UpperSnakeCase(goName string) string {
+	return strings.ToUpper(strings.Join(words(goName), "_"))
+}
+
+// KebabCase converts the Go name to kebab case, keeping acronyms together: "HTTPPort" becomes "http-port".
+func KebabCase(goName string) string {
+	return strings.ToLower(strings.Join(words(goName), "-"))
+}
+
+// DotCase converts the Go name to dot case, keeping acronyms together: "HTTPPort" becomes "http.port".
+func DotCase(goName string) string {
+	return strings.ToLower(strings.Join(words(goName), "."))
+}
+
+// words splits the Go name into words. A word starts at an upper case letter that follows a lower case letter or a digit,
+// and at the last upper case letter of an acronym that is followed by a lower case letter, as in "HTTP" and "Port".
+// Underscores separate words too.
+func words(s string) []string {
+	var (
+		ws    []string
+		start int
+	)
+
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		if r == '_' {
+			if i > start {
+				ws = append(ws, string(rs[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+		prev := rs[i-1]
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+			unicode.IsUpper(prev) && i+1 < len(rs) && unicode.IsLower(rs[i+1]) {
+			ws = append(ws, string(rs[start:i]))
+			start = i
+		}
+	}
+
+	if start < len(rs) {
+		ws = append(ws, string(rs[start:]))
+	}
+	return ws
+}
+
+// envName returns the name of the variable of the field without a prefix:
+// the name in the tag, or the name derived from the Go name by the naming function, if any.
+func (f *field) envName(naming func(string) string) string {
+	if f.hasName || naming == nil {
+		return f.name
+	}
+	return naming(f.goName)
+}
+
+// lookupFold looks up the variable with the name that equals the key ignoring case.
+func (s *getterState) lookupFold(key string) (string, bool, error) {
+	l, ok := s.source.(Lister)
+	if !ok {
+		return "", false, nil
+	}
+
+	match, found := "", false
+	for _, k := range l.Keys() {
+		if strings.EqualFold(k, key) && (!found || k < match) {
+			match, found = k, true
+		}
+	}
+	if !found {
+		return "", false, nil
+	}
+	return lookup(s.ctx, s.source, match)
+}
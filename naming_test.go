@@ -0,0 +1,102 @@
+package envio
+
+import "testing"
+
+func Test_Words(t *testing.T) {
+	tests := []struct {
+		in, snake, kebab string
+	}{
+		{in: "D", snake: "D", kebab: "d"},
+		{in: "MaxConns", snake: "MAX_CONNS", kebab: "max-conns"},
+		{in: "HTTPPort", snake: "HTTP_PORT", kebab: "http-port"},
+		{in: "UserID", snake: "USER_ID", kebab: "user-id"},
+		{in: "ID", snake: "ID", kebab: "id"},
+		{in: "Retry3Times", snake: "RETRY3_TIMES", kebab: "retry3-times"},
+		{in: "TLS_Cert", snake: "TLS_CERT", kebab: "tls-cert"},
+		{in: "ÜberName", snake: "ÜBER_NAME", kebab: "über-name"},
+	}
+
+	for _, tt := range tests {
+		equal(t, tt.snake, UpperSnakeCase(tt.in))
+		equal(t, tt.kebab, KebabCase(tt.in))
+	}
+	equal(t, "http.port", DotCase("HTTPPort"))
+}
+
+type naming struct {
+	HTTPPort int
+	MaxConns int    `env:",m"`
+	Name     string `env:"SERVICE"`
+	DB       struct {
+		Host string
+	}
+	Nodes []struct {
+		Addr string
+	}
+}
+
+func Test_Naming(t *testing.T) {
+	src := Map{
+		"HTTP_PORT":    "8080",
+		"MAX_CONNS":    "10",
+		"SERVICE":      "api",
+		"DB_HOST":      "db",
+		"NODES_0_ADDR": "a",
+	}
+
+	out := new(naming)
+	equal(t, nil, GetFrom(src, out, Naming(UpperSnakeCase), AutoPrefix("_")))
+	equal(t, 8080, out.HTTPPort)
+	equal(t, 10, out.MaxConns)
+	equal(t, "api", out.Name)
+	equal(t, "db", out.DB.Host)
+	equal(t, 1, len(out.Nodes))
+	equal(t, "a", out.Nodes[0].Addr)
+
+	dst := Map{}
+	equal(t, nil, SetTo(dst, out, Naming(UpperSnakeCase), AutoPrefix("_")))
+	equal(t, src, dst)
+
+	dst = Map{}
+	equal(t, nil, SetTo(dst, out, Naming(DotCase), AutoPrefix(".")))
+	equal(t, Map{
+		"http.port":    "8080",
+		"max.conns":    "10",
+		"SERVICE":      "api",
+		"db.host":      "db",
+		"nodes.0.addr": "a",
+	}, dst)
+
+	vars, err := Describe(out, Naming(KebabCase), AutoPrefix("-"))
+	equal(t, nil, err)
+	equal(t, "http-port", vars[0].Name)
+	equal(t, "db-host", vars[3].Name)
+
+	err = GetFrom(Map{}, new(naming), Naming(UpperSnakeCase))
+	equal(t, "env: the required variable $MAX_CONNS is missing", err.Error())
+}
+
+func Test_IgnoreCase(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	src := Map{"host": "localhost", "Port": "80", "PORT_": "1"}
+
+	out := new(config)
+	equal(t, nil, GetFrom(src, out, IgnoreCase()))
+	equal(t, config{Host: "localhost", Port: 80}, *out)
+
+	out = new(config)
+	equal(t, nil, GetFrom(src, out))
+	equal(t, config{}, *out)
+
+	out = new(config)
+	equal(t, nil, GetFrom(Map{"Port": "80", "pORT": "81"}, out, IgnoreCase()))
+	equal(t, 80, out.Port)
+
+	out = new(config)
+	equal(t, nil, GetFrom(Map{"HOST": "a", "host": "b"}, out, IgnoreCase()))
+	equal(t, "a", out.Host)
+}
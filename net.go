@@ -0,0 +1,147 @@
+package envio
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+)
+
+var (
+	urlType          = reflect.TypeOf(url.URL{})
+	ipType           = reflect.TypeOf(net.IP{})
+	ipNetType        = reflect.TypeOf(net.IPNet{})
+	hardwareAddrType = reflect.TypeOf(net.HardwareAddr{})
+	addrType         = reflect.TypeOf(netip.Addr{})
+	addrPortType     = reflect.TypeOf(netip.AddrPort{})
+	prefixType       = reflect.TypeOf(netip.Prefix{})
+	regexpType       = reflect.TypeOf(regexp.Regexp{})
+)
+
+func urlProc(_ *getterState, str string, v reflect.Value) error {
+	u, err := url.Parse(str)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(u).Elem())
+	return nil
+}
+
+func urlFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	u := v.Interface().(url.URL)
+	return append(s.scratch[:0], u.String()...), nil
+}
+
+func ipProc(_ *getterState, str string, v reflect.Value) error {
+	ip := net.ParseIP(str)
+	if ip == nil {
+		return fmt.Errorf("invalid IP address %q", str)
+	}
+	v.Set(reflect.ValueOf(ip))
+	return nil
+}
+
+func ipFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	ip := v.Interface().(net.IP)
+	if len(ip) == 0 {
+		return s.scratch[:0], nil
+	}
+	return append(s.scratch[:0], ip.String()...), nil
+}
+
+// ipNetProc parses a CIDR prefix, the bits of the address outside the mask are cleared.
+func ipNetProc(_ *getterState, str string, v reflect.Value) error {
+	_, n, err := net.ParseCIDR(str)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(n).Elem())
+	return nil
+}
+
+func ipNetFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	n := v.Interface().(net.IPNet)
+	if len(n.IP) == 0 {
+		return s.scratch[:0], nil
+	}
+	return append(s.scratch[:0], n.String()...), nil
+}
+
+func hardwareAddrProc(_ *getterState, str string, v reflect.Value) error {
+	mac, err := net.ParseMAC(str)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(mac))
+	return nil
+}
+
+func hardwareAddrFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	return append(s.scratch[:0], v.Interface().(net.HardwareAddr).String()...), nil
+}
+
+func addrProc(_ *getterState, str string, v reflect.Value) error {
+	a, err := netip.ParseAddr(str)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(a))
+	return nil
+}
+
+func addrFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	a := v.Interface().(netip.Addr)
+	if !a.IsValid() {
+		return s.scratch[:0], nil
+	}
+	return a.AppendTo(s.scratch[:0]), nil
+}
+
+func addrPortProc(_ *getterState, str string, v reflect.Value) error {
+	ap, err := netip.ParseAddrPort(str)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(ap))
+	return nil
+}
+
+func addrPortFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	ap := v.Interface().(netip.AddrPort)
+	if !ap.IsValid() {
+		return s.scratch[:0], nil
+	}
+	return ap.AppendTo(s.scratch[:0]), nil
+}
+
+func prefixProc(_ *getterState, str string, v reflect.Value) error {
+	p, err := netip.ParsePrefix(str)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(p))
+	return nil
+}
+
+func prefixFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	p := v.Interface().(netip.Prefix)
+	if !p.IsValid() {
+		return s.scratch[:0], nil
+	}
+	return p.AppendTo(s.scratch[:0]), nil
+}
+
+func regexpProc(_ *getterState, str string, v reflect.Value) error {
+	re, err := regexp.Compile(str)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(re).Elem())
+	return nil
+}
+
+func regexpFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	return append(s.scratch[:0], addressable(v).Interface().(*regexp.Regexp).String()...), nil
+}
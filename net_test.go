@@ -0,0 +1,117 @@
+package envio
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+type network struct {
+	URL      url.URL          `env:"URL"`
+	Endpoint *url.URL         `env:"ENDPOINT,scheme=https|http"`
+	IP       net.IP           `env:"IP"`
+	IPs      []net.IP         `env:"IPS,sep=','"`
+	Net      net.IPNet        `env:"NET"`
+	Nets     []*net.IPNet     `env:"NETS,sep=','"`
+	MAC      net.HardwareAddr `env:"MAC"`
+	Addr     netip.Addr       `env:"ADDR"`
+	AddrPort netip.AddrPort   `env:"ADDR_PORT"`
+	Prefixes []netip.Prefix   `env:"PREFIXES,sep=','"`
+	Pattern  *regexp.Regexp   `env:"PATTERN"`
+	Missing  *url.URL         `env:"MISSING"`
+}
+
+func Test_Network(t *testing.T) {
+	src := Map{
+		"URL":       "postgres://user@db:5432/app?sslmode=disable",
+		"ENDPOINT":  "https://example.com/api",
+		"IP":        "10.0.0.1",
+		"IPS":       "::1,192.168.0.1",
+		"NET":       "10.1.2.3/8",
+		"NETS":      "fd00::/8,172.16.0.0/12",
+		"MAC":       "00:00:5E:00:53:01",
+		"ADDR":      "::ffff:10.0.0.1",
+		"ADDR_PORT": "[::1]:8080",
+		"PREFIXES":  "10.0.0.0/8,fd00::/8",
+		"PATTERN":   "^a+$",
+	}
+
+	out := new(network)
+	equal(t, nil, GetFrom(src, out))
+	equal(t, "db:5432", out.URL.Host)
+	equal(t, "/api", out.Endpoint.Path)
+	equal(t, net.ParseIP("10.0.0.1"), out.IP)
+	equal(t, []net.IP{net.ParseIP("::1"), net.ParseIP("192.168.0.1")}, out.IPs)
+	equal(t, "10.0.0.0/8", out.Net.String())
+	equal(t, "fd00::/8", out.Nets[0].String())
+	equal(t, net.HardwareAddr{0, 0, 0x5e, 0, 0x53, 1}, out.MAC)
+	equal(t, netip.MustParseAddr("::ffff:10.0.0.1"), out.Addr)
+	equal(t, netip.MustParseAddrPort("[::1]:8080"), out.AddrPort)
+	equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")}, out.Prefixes)
+	equal(t, true, out.Pattern.MatchString("aaa"))
+	equal(t, (*url.URL)(nil), out.Missing)
+
+	dst := Map{}
+	equal(t, nil, SetTo(dst, out))
+	src["NET"] = "10.0.0.0/8"
+	src["MAC"] = "00:00:5e:00:53:01"
+	equal(t, src, dst)
+
+	equal(t, nil, SetTo(dst, &network{}))
+	equal(t, "", dst["IP"])
+	equal(t, "", dst["ADDR"])
+
+	for name, value := range map[string]string{
+		"IP":        "10.0.0.256",
+		"NET":       "10.0.0.0",
+		"MAC":       "00:00",
+		"ADDR":      "localhost",
+		"ADDR_PORT": "::1",
+		"PATTERN":   "(",
+		"URL":       "http://[::1",
+	} {
+		err := GetFrom(Map{name: value}, new(network))
+		var fe *FieldError
+		equal(t, true, errors.As(err, &fe))
+		equal(t, name, fe.Name)
+	}
+
+	err := GetFrom(Map{"ENDPOINT": "ftp://example.com"}, new(network))
+	var re *RuleError
+	equal(t, true, errors.As(err, &re))
+	equal(t, `the scheme "ftp" is not one of https|http`, re.Msg)
+}
+
+func Test_NetworkMapKeys(t *testing.T) {
+	type weights struct {
+		Weights map[netip.Addr]int       `env:"WEIGHTS"`
+		Regions map[netip.Addr]*upstream `env:"REGION"`
+	}
+
+	v := &weights{Weights: make(map[netip.Addr]int), Regions: make(map[netip.Addr]*upstream)}
+	for i := 8; i > 0; i-- {
+		addr := netip.AddrFrom4([4]byte{10, 0, 0, byte(i)})
+		v.Weights[addr] = i
+		v.Regions[addr] = &upstream{Host: addr.String()}
+	}
+
+	exp := "10.0.0.1=1,10.0.0.2=2,10.0.0.3=3,10.0.0.4=4,10.0.0.5=5,10.0.0.6=6,10.0.0.7=7,10.0.0.8=8"
+	var first []string
+	for i := 0; i < 20; i++ {
+		env, err := Marshal(v)
+		equal(t, nil, err)
+		if first == nil {
+			first = env
+		}
+		equal(t, first, env)
+
+		dst := &Environ{}
+		equal(t, nil, SetTo(dst, v))
+		equal(t, "WEIGHTS="+exp, (*dst)[0])
+		equal(t, "REGION_10.0.0.1_HOST=10.0.0.1", (*dst)[1])
+		equal(t, "REGION_10.0.0.8_PORT=0", (*dst)[len(*dst)-1])
+	}
+}
@@ -0,0 +1,75 @@
+package envio
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigRatType   = reflect.TypeOf(big.Rat{})
+)
+
+// parseBase returns the base that integers of the field are parsed in, 10 unless the base tag option says otherwise.
+// The base 0 means that the base is implied by the prefix, as in 0x1F, 0o755 or 0b101, and that underscores are allowed.
+func (f *field) parseBase() int {
+	if f.hasBase {
+		return f.base
+	}
+	return 10
+}
+
+// formatBase returns the base that integers of the field are formatted in, 10 for the base 0.
+func (f *field) formatBase() int {
+	if f.base == 0 {
+		return 10
+	}
+	return f.base
+}
+
+// rangeError describes a value that does not fit in the bit size of its type.
+func rangeError(str string, bitSize int, kind string) error {
+	return fmt.Errorf("%w: %q overflows %d-bit %s", strconv.ErrRange, str, bitSize, kind)
+}
+
+func bigIntProc(s *getterState, str string, v reflect.Value) error {
+	z, ok := new(big.Int).SetString(str, s.field.parseBase())
+	if !ok {
+		return strconv.ErrSyntax
+	}
+	v.Set(reflect.ValueOf(z).Elem())
+	return nil
+}
+
+func bigIntFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	return addressable(v).Interface().(*big.Int).Append(s.scratch[:0], s.field.formatBase()), nil
+}
+
+func bigFloatProc(_ *getterState, str string, v reflect.Value) error {
+	z, ok := new(big.Float).SetString(str)
+	if !ok {
+		return strconv.ErrSyntax
+	}
+	v.Set(reflect.ValueOf(z).Elem())
+	return nil
+}
+
+func bigFloatFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	return addressable(v).Interface().(*big.Float).Append(s.scratch[:0], 'g', -1), nil
+}
+
+func bigRatProc(_ *getterState, str string, v reflect.Value) error {
+	z, ok := new(big.Rat).SetString(str)
+	if !ok {
+		return strconv.ErrSyntax
+	}
+	v.Set(reflect.ValueOf(z).Elem())
+	return nil
+}
+
+func bigRatFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	return append(s.scratch[:0], addressable(v).Interface().(*big.Rat).RatString()...), nil
+}
@@ -0,0 +1,131 @@
+package envio
+
+import (
+	"math/big"
+	"testing"
+)
+
+type numbers struct {
+	Hex     int64             `env:"HEX,base=16"`
+	Auto    int               `env:"AUTO,base=0"`
+	Mode    uint32            `env:"MODE,base=8"`
+	Masks   []uint8           `env:"MASKS,base=2,sep=','"`
+	C64     complex64         `env:"C64"`
+	C128    complex128        `env:"C128"`
+	Roots   []complex128      `env:"ROOTS,sep=' '"`
+	Int     *big.Int          `env:"INT"`
+	HexInt  *big.Int          `env:"HEX_INT,base=16"`
+	Float   *big.Float        `env:"FLOAT"`
+	Rat     *big.Rat          `env:"RAT"`
+	Ints    []*big.Int        `env:"INTS,sep=','"`
+	Missing *big.Int          `env:"MISSING"`
+	Weights map[complex64]int `env:"WEIGHTS"`
+}
+
+func Test_Numbers(t *testing.T) {
+	src := Map{
+		"HEX":     "1f",
+		"AUTO":    "0x1_F",
+		"MODE":    "755",
+		"MASKS":   "101,11",
+		"C64":     "1+2i",
+		"C128":    "(-1.5-0.5i)",
+		"ROOTS":   "1 -1 1i",
+		"INT":     "123456789012345678901234567890",
+		"HEX_INT": "ff",
+		"FLOAT":   "1.5e100",
+		"RAT":     "1/3",
+		"INTS":    "1,-2",
+		"WEIGHTS": "1+1i=2,1=1",
+	}
+
+	out := new(numbers)
+	equal(t, nil, GetFrom(src, out))
+	equal(t, int64(31), out.Hex)
+	equal(t, 31, out.Auto)
+	equal(t, uint32(0o755), out.Mode)
+	equal(t, []uint8{5, 3}, out.Masks)
+	equal(t, complex64(1+2i), out.C64)
+	equal(t, -1.5-0.5i, out.C128)
+	equal(t, []complex128{1, -1, 1i}, out.Roots)
+	equal(t, "123456789012345678901234567890", out.Int.String())
+	equal(t, int64(255), out.HexInt.Int64())
+	equal(t, "1.5e+100", out.Float.Text('g', -1))
+	equal(t, "1/3", out.Rat.RatString())
+	equal(t, "1 -2", out.Ints[0].String()+" "+out.Ints[1].String())
+	equal(t, (*big.Int)(nil), out.Missing)
+	equal(t, map[complex64]int{1 + 1i: 2, 1: 1}, out.Weights)
+
+	dst := Map{}
+	equal(t, nil, SetTo(dst, out))
+	equal(t, Map{
+		"HEX":     "1f",
+		"AUTO":    "31",
+		"MODE":    "755",
+		"MASKS":   "101,11",
+		"C64":     "(1+2i)",
+		"C128":    "(-1.5-0.5i)",
+		"ROOTS":   "(1+0i) (-1+0i) (0+1i)",
+		"INT":     "123456789012345678901234567890",
+		"HEX_INT": "ff",
+		"FLOAT":   "1.5e+100",
+		"RAT":     "1/3",
+		"INTS":    "1,-2",
+		"WEIGHTS": "(1+0i)=1,(1+1i)=2",
+	}, dst)
+
+	back := new(numbers)
+	equal(t, nil, GetFrom(dst, back))
+	equal(t, out.Roots, back.Roots)
+	equal(t, out.Int.String(), back.Int.String())
+}
+
+func Test_NumbersErrors(t *testing.T) {
+	tests := []struct {
+		v   any
+		src Map
+		err string
+	}{
+		{
+			v:   new(struct{ A int8 }),
+			src: Map{"A": "300"},
+			err: `env: cannot get data into Go value of type int8: value out of range: "300" overflows 8-bit integer`,
+		},
+		{
+			v:   new(struct{ A uint16 }),
+			src: Map{"A": "70000"},
+			err: `env: cannot get data into Go value of type uint16: value out of range: "70000" overflows 16-bit unsigned integer`,
+		},
+		{
+			v:   new(struct{ A float32 }),
+			src: Map{"A": "1e40"},
+			err: `env: cannot get data into Go value of type float32: value out of range: "1e40" overflows 32-bit float`,
+		},
+		{
+			v:   new(struct{ A complex64 }),
+			src: Map{"A": "1e40i"},
+			err: `env: cannot get data into Go value of type complex64: value out of range: "1e40i" overflows 64-bit complex`,
+		},
+		{
+			v:   new(struct{ A []int8 }),
+			src: Map{"A": "1" + string(envSeparator) + "-129"},
+			err: `env: cannot get data into Go value of type []int8: value out of range: "-129" overflows 8-bit integer`,
+		},
+		{
+			v:   new(struct{ A *big.Int }),
+			src: Map{"A": "12x"},
+			err: `env: cannot get data into Go value of type big.Int: invalid syntax`,
+		},
+		{
+			v: new(struct {
+				A int `env:"A,base=37"`
+			}),
+			err: `env: invalid base "37" in Go struct field .A`,
+		},
+	}
+
+	for _, test := range tests {
+		err := GetFrom(test.src, test.v)
+		equal(t, test.err, err.Error())
+	}
+}
@@ -0,0 +1,74 @@
+package envio
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[reflect.Type]procs{}
+)
+
+// RegisterType registers the parser and the formatter of values of the type T,
+// for types that cannot implement Getter and Setter, such as types of other modules.
+// Registered types take precedence over all other handling of the type, also as elements of lists and maps.
+// Registering a type again replaces its functions, and registering after the first use of the type is allowed.
+// RegisterType panics if parse or format is nil.
+func RegisterType[T any](parse func(string) (T, error), format func(T) (string, error)) {
+	if parse == nil || format == nil {
+		panic(name + ": RegisterType with a nil function")
+	}
+
+	p := procs{
+		get: func(_ *getterState, str string, v reflect.Value) error {
+			r, err := parse(str)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(&r).Elem())
+			return nil
+		},
+		set: func(s *setterState, v reflect.Value) ([]byte, error) {
+			// The value of an interface type may be nil.
+			r, _ := v.Interface().(T)
+			str, err := format(r)
+			if err != nil {
+				return nil, err
+			}
+			return append(s.scratch[:0], str...), nil
+		},
+	}
+
+	registryMu.Lock()
+	registry[reflect.TypeOf((*T)(nil)).Elem()] = p
+	registryMu.Unlock()
+
+	// The functions of the type and of the types using it, such as its lists, are cached.
+	resetCaches()
+}
+
+// typeProcs returns the parser and the formatter of a registered or a built-in type.
+func typeProcs(t reflect.Type) (procs, bool) {
+	registryMu.RLock()
+	p, ok := registry[t]
+	registryMu.RUnlock()
+	if ok {
+		return p, true
+	}
+
+	p, ok = builtinTypes[t]
+	return p, ok
+}
+
+// resetCaches removes all functions and fields from the caches.
+func resetCaches() {
+	functionsCache.Range(func(k, _ any) bool {
+		functionsCache.Delete(k)
+		return true
+	})
+	fieldCache.Range(func(k, _ any) bool {
+		fieldCache.Delete(k)
+		return true
+	})
+}
@@ -0,0 +1,125 @@
+package envio
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type severity int
+
+type decimal struct {
+	units int64
+	cents int64
+}
+
+type registered struct {
+	Severity   severity            `env:"SEVERITY"`
+	Severities []severity          `env:"SEVERITIES,sep=','"`
+	Nested     [][]severity        `env:"NESTED,sep=;"`
+	ByName     map[string]severity `env:"BY_NAME"`
+	Pointer    *severity           `env:"POINTER"`
+	Price      decimal             `env:"PRICE"`
+	Prices     [2]decimal          `env:"PRICES,sep=' '"`
+}
+
+func parseSeverity(str string) (severity, error) {
+	switch str {
+	case "low":
+		return 1, nil
+	case "high":
+		return 2, nil
+	default:
+		return 0, fmt.Errorf("unknown severity %q", str)
+	}
+}
+
+func formatSeverity(s severity) (string, error) {
+	switch s {
+	case 1:
+		return "low", nil
+	case 2:
+		return "high", nil
+	default:
+		return "", errors.New("invalid severity")
+	}
+}
+
+func parseDecimal(str string) (decimal, error) {
+	units, cents, _ := strings.Cut(str, ".")
+	u, err := strconv.ParseInt(units, 10, 64)
+	if err != nil {
+		return decimal{}, err
+	}
+	c, err := strconv.ParseInt(cents, 10, 64)
+	if err != nil {
+		return decimal{}, err
+	}
+	return decimal{units: u, cents: c}, nil
+}
+
+func formatDecimal(d decimal) (string, error) {
+	return fmt.Sprintf("%d.%02d", d.units, d.cents), nil
+}
+
+func Test_RegisterType(t *testing.T) {
+	src := Map{"PRICE": "1.50"}
+
+	// Before the registration, decimal is a struct without variables.
+	out := new(registered)
+	equal(t, nil, GetFrom(src, out))
+	equal(t, decimal{}, out.Price)
+
+	// The registry is global, so the registrations are undone for reruns and other tests.
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, reflect.TypeOf(severity(0)))
+		delete(registry, reflect.TypeOf(decimal{}))
+		registryMu.Unlock()
+		resetCaches()
+	})
+
+	RegisterType(parseSeverity, formatSeverity)
+	RegisterType(parseDecimal, formatDecimal)
+
+	src = Map{
+		"SEVERITY":   "high",
+		"SEVERITIES": "low,high",
+		"NESTED":     "low,low;high",
+		"BY_NAME":    "a=low,b=high",
+		"POINTER":    "low",
+		"PRICE":      "1.50",
+		"PRICES":     "0.99 10.00",
+	}
+
+	out = new(registered)
+	equal(t, nil, GetFrom(src, out))
+	low := severity(1)
+	equal(t, &registered{
+		Severity:   2,
+		Severities: []severity{1, 2},
+		Nested:     [][]severity{{1, 1}, {2}},
+		ByName:     map[string]severity{"a": 1, "b": 2},
+		Pointer:    &low,
+		Price:      decimal{1, 50},
+		Prices:     [2]decimal{{0, 99}, {10, 0}},
+	}, out)
+
+	dst := Map{}
+	equal(t, nil, SetTo(dst, out))
+	equal(t, src, dst)
+
+	err := GetFrom(Map{"SEVERITY": "none"}, new(registered))
+	equal(t, `env: cannot get data into Go struct field registered.SEVERITY of type envio.severity: unknown severity "none"`, err.Error())
+
+	err = SetTo(Map{}, &registered{Severity: 3})
+	equal(t, "env: cannot set data from Go struct field registered.SEVERITY of type envio.severity: invalid severity", err.Error())
+
+	defer func() {
+		equal(t, "env: RegisterType with a nil function", recover())
+	}()
+	RegisterType[severity](nil, formatSeverity)
+}
@@ -1,17 +1,21 @@
 package envio
 
 import (
+	"bytes"
+	"context"
+	"encoding"
 	"errors"
-	"os"
+	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"sync"
 )
 
 const setError = "set data from"
 
-func (e *engine) set(v any) error {
-	s := e.newSetState()
+func (e *engine) set(ctx context.Context, v any) error {
+	s := e.newSetState(ctx)
 	defer setStatePool.Put(s)
 
 	s.set(v)
@@ -20,25 +24,43 @@ func (e *engine) set(v any) error {
 
 type setterState struct {
 	*engine
-	context
+	fieldContext
 	scratch [64]byte
+	ctx     context.Context
+	// unsetting makes the setter remove the variables instead of setting them.
+	unsetting bool
+	// patching makes the setter set only the variables that hold other values,
+	// written lists their names and the names of the removed variables.
+	patching bool
+	written  []string
 }
 
 var setStatePool sync.Pool
 
-func (e *engine) newSetState() *setterState {
+func (e *engine) newSetState(ctx context.Context) *setterState {
 	if p := setStatePool.Get(); p != nil {
 		s := p.(*setterState)
-		s.err = nil
+		s.engine = e
+		s.ctx = ctx
+		s.unsetting = false
+		s.patching = false
+		s.written = nil
+		s.reset()
+		s.fieldContext.naming = e.naming
 		return s
 	}
 
-	s := &setterState{engine: e}
-	s.field = new(field)
+	s := &setterState{engine: e, ctx: ctx}
+	s.reset()
+	s.fieldContext.naming = e.naming
 	return s
 }
 
 func (s *setterState) set(v any) {
+	if v == nil {
+		s.err = fmt.Errorf("%s: the input value is nil", name)
+		return
+	}
 	if err := s.reflectValue(reflect.ValueOf(v)); err != nil {
 		if !errors.Is(err, errExist) {
 			s.setError(name, setError, err)
@@ -47,12 +69,42 @@ func (s *setterState) set(v any) {
 }
 
 func (s *setterState) reflectValue(v reflect.Value) error {
-	s.context.field.typ = v.Type()
-	return s.cachedFunctions(s.context.field.typ).setterFunc(s, v)
+	s.typ = v.Type()
+	return s.cachedFunctions(s.typ).setterFunc(s, v)
 }
 
 func (s *setterState) setEnv(v []byte) error {
-	return os.Setenv(s.field.name, string(v))
+	return s.setVar(s.varName(), v)
+}
+
+// setVar sets the variable named by the key, or removes it when unsetting.
+// When patching, a variable that already holds the value is not set.
+func (s *setterState) setVar(key string, v []byte) error {
+	if s.unsetting {
+		return s.unsetVar(key, v, true)
+	}
+	if s.patching {
+		str, ok, err := lookup(s.ctx, s.source, key)
+		if err != nil || ok && str == string(v) {
+			return err
+		}
+		if err = s.writeVar(key, v); err == nil {
+			s.written = append(s.written, key)
+		}
+		return err
+	}
+	return s.writeVar(key, v)
+}
+
+// writeVar writes the variable named by the key to the sink.
+func (s *setterState) writeVar(key string, v []byte) error {
+	if sink, ok := s.sink.(ContextSink); ok {
+		return sink.SetContext(s.ctx, key, string(v))
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	return s.sink.Set(key, string(v))
 }
 
 type setterFunc func(*setterState, reflect.Value) error
@@ -68,29 +120,42 @@ func valueFromPtr(v reflect.Value) reflect.Value {
 }
 
 func (f *structFields) set(s *setterState, v reflect.Value) (err error) {
+	field, typ := s.field, s.typ
 	s.structName = v.Type().Name()
 
 	for _, s.field = range *f {
+		s.typ = s.field.typ
 		rv := v.Field(s.field.index)
 
+		if s.field.err != nil {
+			s.err = s.field.err
+			return errExist
+		}
+
 		// If the environment variable is mandatory,
 		// then to avoid overwriting the value, ignore the field if it is empty.
-		if s.field.mandatory && isEmptyValue(rv) {
+		// When unsetting, ignore the field only if mandatory variables are kept.
+		if s.field.mandatory && (s.unsetting && s.keepMandatory || !s.unsetting && isEmptyValue(rv)) {
 			continue
 		}
 
+		prefix := s.prefix
+		s.prefix += s.fieldPrefix(s.field)
+
 		if s.field.embedded != nil {
-			if err = s.field.embedded.set(s, valueFromPtr(rv)); err != nil {
-				return
-			}
-			continue
+			err = s.field.embedded.set(s, valueFromPtr(rv))
+		} else {
+			err = s.field.functions.setterFunc(s, rv)
 		}
 
-		if err = s.field.functions.setterFunc(s, rv); err != nil {
+		if err != nil {
 			return
 		}
+
+		s.prefix = prefix
 	}
 
+	s.field, s.typ = field, typ
 	return
 }
 
@@ -112,24 +177,60 @@ func setSetter(s *setterState, v reflect.Value) error {
 	return s.setEnv(p)
 }
 
+// marshalerSetter returns a setter for types implementing encoding.TextMarshaler or,
+// for fields with the raw option, encoding.BinaryMarshaler.
+// Values of other fields are set by the fallback setter.
+func marshalerSetter(t reflect.Type, fallback setterFunc) setterFunc {
+	pt := reflect.PointerTo(t)
+	text, binary := pt.Implements(textMarshaler), pt.Implements(binaryMarshaler)
+
+	return func(s *setterState, v reflect.Value) error {
+		var (
+			p   []byte
+			err error
+		)
+
+		switch {
+		case s.field.raw && binary:
+			p, err = addressable(v).Interface().(encoding.BinaryMarshaler).MarshalBinary()
+		case text:
+			p, err = textFormat(s, v)
+		default:
+			return fallback(s, v)
+		}
+
+		if err != nil {
+			return err
+		}
+		return s.setEnv(p)
+	}
+}
+
 func boolSetter(s *setterState, v reflect.Value) error {
 	return s.setEnv(strconv.AppendBool(s.scratch[:0], v.Bool()))
 }
 
 func intSetter(s *setterState, v reflect.Value) error {
-	return s.setEnv(strconv.AppendInt(s.scratch[:0], v.Int(), 10))
+	return s.setEnv(strconv.AppendInt(s.scratch[:0], v.Int(), s.field.formatBase()))
 }
 
 func uintSetter(s *setterState, v reflect.Value) error {
-	return s.setEnv(strconv.AppendUint(s.scratch[:0], v.Uint(), 10))
+	return s.setEnv(strconv.AppendUint(s.scratch[:0], v.Uint(), s.field.formatBase()))
 }
 
 func floatSetter(s *setterState, v reflect.Value) error {
 	return s.setEnv(strconv.AppendFloat(s.scratch[:0], v.Float(), 'g', -1, bitSize(v.Kind())))
 }
 
+func complexSetter(s *setterState, v reflect.Value) error {
+	return s.setEnv(append(s.scratch[:0], strconv.FormatComplex(v.Complex(), 'g', -1, bitSize(v.Kind()))...))
+}
+
 func interfaceSetter(s *setterState, v reflect.Value) error {
 	if v.IsNil() {
+		if s.unsetting {
+			return s.unsetEnv(nil, false)
+		}
 		s.err = ErrNilInterface
 		return errExist
 	}
@@ -137,44 +238,98 @@ func interfaceSetter(s *setterState, v reflect.Value) error {
 }
 
 func pointerSetter(s *setterState, v reflect.Value) error {
+	// A nil pointer means the variable is not configured, so it is not set.
+	if v.IsNil() && !s.isNested(v.Type()) {
+		if s.unsetting {
+			return s.unsetEnv(nil, false)
+		}
+		return nil
+	}
 	return s.reflectValue(valueFromPtr(v))
 }
 
-func setProc(t reflect.Type) func(*setterState, reflect.Value) []byte {
-	switch t.Elem().Kind() {
+// setProcFunc formats the value.
+type setProcFunc func(*setterState, reflect.Value) ([]byte, error)
+
+// setProc returns a formatter for values of the type t.
+func setProc(t reflect.Type) setProcFunc {
+	if p, ok := typeProcs(t); ok {
+		return p.set
+	}
+
+	if pt := reflect.PointerTo(t); t.Kind() != reflect.Pointer {
+		switch {
+		case pt.Implements(setter):
+			return setterFormat
+		case pt.Implements(textMarshaler):
+			return textFormat
+		}
+	}
+
+	switch t.Kind() {
 	case reflect.Bool:
-		return func(s *setterState, v reflect.Value) []byte {
-			return strconv.AppendBool(s.scratch[:0], v.Bool())
+		return func(s *setterState, v reflect.Value) ([]byte, error) {
+			return strconv.AppendBool(s.scratch[:0], v.Bool()), nil
 		}
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return func(s *setterState, v reflect.Value) []byte {
-			return strconv.AppendInt(s.scratch[:0], v.Int(), 10)
+		return func(s *setterState, v reflect.Value) ([]byte, error) {
+			return strconv.AppendInt(s.scratch[:0], v.Int(), s.field.formatBase()), nil
 		}
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		return func(s *setterState, v reflect.Value) []byte {
-			return strconv.AppendUint(s.scratch[:0], v.Uint(), 10)
+		return func(s *setterState, v reflect.Value) ([]byte, error) {
+			return strconv.AppendUint(s.scratch[:0], v.Uint(), s.field.formatBase()), nil
 		}
 	case reflect.Float32, reflect.Float64:
-		return func(s *setterState, v reflect.Value) []byte {
-			return strconv.AppendFloat(s.scratch[:0], v.Float(), 'g', -1, bitSize(v.Kind()))
+		return func(s *setterState, v reflect.Value) ([]byte, error) {
+			return strconv.AppendFloat(s.scratch[:0], v.Float(), 'g', -1, bitSize(v.Kind())), nil
+		}
+	case reflect.Complex64, reflect.Complex128:
+		return func(s *setterState, v reflect.Value) ([]byte, error) {
+			return append(s.scratch[:0], strconv.FormatComplex(v.Complex(), 'g', -1, bitSize(v.Kind()))...), nil
 		}
 	case reflect.Pointer:
-		return func(s *setterState, v reflect.Value) []byte {
-			proc := setProc(v.Type())
-			v = valueFromPtr(v)
-			return proc(s, v)
+		proc := setProc(t.Elem())
+		if proc == nil {
+			return nil
+		}
+		return func(s *setterState, v reflect.Value) ([]byte, error) {
+			return proc(s, valueFromPtr(v))
 		}
 	case reflect.String:
-		return func(s *setterState, v reflect.Value) []byte {
-			return append(s.scratch[:0], v.String()...)
+		return func(s *setterState, v reflect.Value) ([]byte, error) {
+			return append(s.scratch[:0], v.String()...), nil
+		}
+	case reflect.Array, reflect.Slice:
+		// An element that is a list itself uses the inner separator.
+		proc := setProc(t.Elem())
+		if proc == nil {
+			return nil
+		}
+		return func(s *setterState, v reflect.Value) ([]byte, error) {
+			return formatList(s, nil, s.fieldInnerSeparator(s.field), proc, v)
 		}
 	default:
 		return nil
 	}
 }
 
+// addressable returns a pointer to a copy of v, so that methods with pointer receivers can be called.
+func addressable(v reflect.Value) reflect.Value {
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p
+}
+
+func setterFormat(_ *setterState, v reflect.Value) ([]byte, error) {
+	return addressable(v).Interface().(Setter).SetENV()
+}
+
+func textFormat(_ *setterState, v reflect.Value) ([]byte, error) {
+	return addressable(v).Interface().(encoding.TextMarshaler).MarshalText()
+}
+
 func sliceSetter(t reflect.Type) setterFunc {
-	proc := setProc(t)
+	proc := setProc(t.Elem())
 	if proc == nil {
 		return unsupportedTypeSetter
 	}
@@ -188,12 +343,71 @@ func sliceSetter(t reflect.Type) setterFunc {
 			return s.setEnv(buf)
 		}
 
+		buf, err := formatList(s, nil, s.fieldSeparator(s.field), proc, v)
+		if err != nil {
+			return err
+		}
+		return s.setEnv(buf)
+	}
+}
+
+// mapKey is a key of a map with its formatted value.
+type mapKey struct {
+	value reflect.Value
+	text  []byte
+}
+
+// sortedKeys formats the keys of the map with the proc and sorts them, so that maps are set deterministically.
+// Keys of scalar kinds are compared by value, others, such as netip.Addr or time.Time, by their formatted values.
+func sortedKeys(s *setterState, v reflect.Value, proc setProcFunc) ([]mapKey, error) {
+	keys := make([]mapKey, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		p, err := proc(s, k)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, mapKey{value: k, text: append([]byte(nil), p...)})
+	}
+
+	ordered := orderedKind(v.Type().Key())
+	sort.Slice(keys, func(i, j int) bool {
+		if ordered {
+			return lessValue(keys[i].value, keys[j].value)
+		}
+		return bytes.Compare(keys[i].text, keys[j].text) < 0
+	})
+	return keys, nil
+}
+
+func mapSetter(t reflect.Type) setterFunc {
+	keyProc, valueProc := setProc(t.Key()), setProc(t.Elem())
+	if keyProc == nil || valueProc == nil {
+		return unsupportedTypeSetter
+	}
+
+	return func(s *setterState, v reflect.Value) error {
+		keys, err := sortedKeys(s, v, keyProc)
+		if err != nil {
+			return err
+		}
+
+		pairSep, kvSep := s.fieldMapSeparators(s.field)
 		buf := make([]byte, 0)
-		for i := 0; i < v.Len(); i++ {
+		for i, k := range keys {
 			if i > 0 {
-				buf = append(buf, s.separator...)
+				buf = append(buf, pairSep...)
 			}
-			buf = append(buf, proc(s, v.Index(i))...)
+			buf = s.escaping.escape(buf, k.text, pairSep, kvSep)
+			buf = append(buf, kvSep...)
+			ev := v.MapIndex(k.value)
+			if ev.Kind() == reflect.Pointer && ev.IsNil() {
+				continue
+			}
+			p, err := valueProc(s, ev)
+			if err != nil {
+				return err
+			}
+			buf = s.escaping.escape(buf, p, pairSep, kvSep)
 		}
 		return s.setEnv(buf)
 	}
@@ -203,6 +417,23 @@ func stringSetter(s *setterState, v reflect.Value) error {
 	return s.setEnv(append(s.scratch[:0], v.String()...))
 }
 
+// scalarSetter returns a setter that formats the value with the proc.
+func scalarSetter(proc setProcFunc) setterFunc {
+	return func(s *setterState, v reflect.Value) error {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			if s.unsetting {
+				return s.unsetEnv(nil, false)
+			}
+			return nil
+		}
+		p, err := proc(s, v)
+		if err != nil {
+			return err
+		}
+		return s.setEnv(p)
+	}
+}
+
 func structSetter(s *setterState, v reflect.Value) error {
 	f := s.cachedFields(v.Type())
 	return f.set(s, reflect.ValueOf(v.Interface()))
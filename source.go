@@ -0,0 +1,180 @@
+package envio
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Source is the interface implemented by stores that variables can be got from.
+type Source interface {
+	// Lookup returns the value of the variable named by the key
+	// and reports whether the variable is present.
+	Lookup(key string) (string, bool)
+}
+
+// ContextSource is the interface implemented by sources whose lookups may block.
+// If a source implements ContextSource, LookupContext is used instead of Lookup.
+type ContextSource interface {
+	Source
+	LookupContext(ctx context.Context, key string) (string, bool, error)
+}
+
+// Lister is the interface implemented by sources whose variables can be listed,
+// which maps of structs are got from.
+type Lister interface {
+	Source
+	// Keys returns the names of the variables.
+	Keys() []string
+}
+
+// Sink is the interface implemented by stores that variables can be set to.
+type Sink interface {
+	// Set sets the value of the variable named by the key.
+	Set(key, value string) error
+}
+
+// ContextSink is the interface implemented by sinks whose writes may block.
+// If a sink implements ContextSink, SetContext is used instead of Set.
+type ContextSink interface {
+	Sink
+	SetContext(ctx context.Context, key, value string) error
+}
+
+// GetFrom gets values from the source to the value pointed to by v.
+// If v is nil or not a pointer, GetFrom returns a getter error.
+func GetFrom(src Source, v any, opts ...Option) error {
+	return GetContext(context.Background(), src, v, opts...)
+}
+
+// GetContext is like GetFrom but stops when the context is done.
+func GetContext(ctx context.Context, src Source, v any, opts ...Option) error {
+	c := *newEngine(opts)
+	c.source = src
+	return c.get(ctx, v)
+}
+
+// SetTo sets values from v to the sink.
+// If v is nil, SetTo returns a setter error.
+func SetTo(sink Sink, v any, opts ...Option) error {
+	return SetContext(context.Background(), sink, v, opts...)
+}
+
+// SetContext is like SetTo but stops when the context is done.
+// If the sink is also a Source, stale variables of lists of structs are looked up in it.
+func SetContext(ctx context.Context, sink Sink, v any, opts ...Option) error {
+	c := *newEngine(opts)
+	c.sink = sink
+	c.source, _ = sink.(Source)
+	return c.set(ctx, v)
+}
+
+// Marshal returns the variables that Set would set from v as "KEY=VALUE" entries sorted by key,
+// in the form used by os.Environ and exec.Cmd.Env, without changing the environment.
+func Marshal(v any, opts ...Option) ([]string, error) {
+	m := make(Map)
+	if err := SetTo(m, v, opts...); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	env := make([]string, len(keys))
+	for i, k := range keys {
+		env[i] = k + "=" + m[k]
+	}
+	return env, nil
+}
+
+// Unmarshal gets values from the "KEY=VALUE" entries to the value pointed to by v, as Get does from the environment.
+// If a key occurs several times, the last entry wins.
+func Unmarshal(env []string, v any, opts ...Option) error {
+	return GetFrom(Environ(env), v, opts...)
+}
+
+// Process is the Source and Sink of the environment of the current process.
+type Process struct{}
+
+// Lookup returns the value of the environment variable named by the key.
+func (Process) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// Keys returns the names of the environment variables.
+func (Process) Keys() []string {
+	return Environ(os.Environ()).Keys()
+}
+
+// Set sets the value of the environment variable named by the key.
+func (Process) Set(key, value string) error {
+	return os.Setenv(key, value)
+}
+
+// Map is the Source and Sink backed by a map.
+type Map map[string]string
+
+// Lookup returns the value stored in the map under the key.
+func (m Map) Lookup(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+// Keys returns the keys of the map.
+func (m Map) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Set stores the value in the map under the key.
+func (m Map) Set(key, value string) error {
+	m[key] = value
+	return nil
+}
+
+// Environ is the Source of "KEY=VALUE" entries in the form returned by os.Environ.
+// A pointer to Environ is also a Sink. If a key occurs several times, the last entry wins.
+type Environ []string
+
+// Lookup returns the value of the last entry with the key.
+func (e Environ) Lookup(key string) (string, bool) {
+	for i := len(e) - 1; i >= 0; i-- {
+		if k, v, ok := strings.Cut(e[i], "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Keys returns the keys of the entries, each key once.
+func (e Environ) Keys() []string {
+	keys := make([]string, 0, len(e))
+	seen := make(map[string]bool, len(e))
+	for _, entry := range e {
+		if k, _, ok := strings.Cut(entry, "="); ok && !seen[k] {
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+	return keys
+}
+
+// Set replaces the value of the last entry with the key or appends a new entry.
+func (e *Environ) Set(key, value string) error {
+	entry := key + "=" + value
+	for i := len(*e) - 1; i >= 0; i-- {
+		if k, _, ok := strings.Cut((*e)[i], "="); ok && k == key {
+			(*e)[i] = entry
+			return nil
+		}
+	}
+	*e = append(*e, entry)
+	return nil
+}
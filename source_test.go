@@ -0,0 +1,119 @@
+package envio
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+type blockingSource struct {
+	Map
+}
+
+func (b blockingSource) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+	v, ok := b.Lookup(key)
+	return v, ok, nil
+}
+
+func Test_Map(t *testing.T) {
+	m := Map{}
+	in := &simple{A: "test", B: true, C: 28, D: 3.14}
+
+	equal(t, nil, SetTo(m, in))
+	equal(t, Map{"ENV_A": "test", "ENV_B": "true", "ENV_C": "28", "D": "3.14"}, m)
+
+	out := new(simple)
+	equal(t, nil, GetFrom(m, out))
+	equal(t, in, out)
+}
+
+func Test_Environ(t *testing.T) {
+	src := Environ{"ENV_A=first", "ENV_B=true", "ENV_A=test", "INVALID", "ENV_C=2=8"}
+
+	v, ok := src.Lookup("ENV_A")
+	equal(t, "test", v)
+	equal(t, true, ok)
+
+	_, ok = src.Lookup("INVALID")
+	equal(t, false, ok)
+
+	equal(t, nil, src.Set("ENV_A", "updated"))
+	equal(t, nil, src.Set("D", "3.14"))
+	equal(t, Environ{"ENV_A=first", "ENV_B=true", "ENV_A=updated", "INVALID", "ENV_C=2=8", "D=3.14"}, src)
+
+	out := new(struct {
+		A string  `env:"ENV_A"`
+		B bool    `env:"ENV_B"`
+		C string  `env:"ENV_C"`
+		D float64 `env:"D"`
+	})
+	equal(t, nil, GetFrom(src, out))
+	equal(t, "updated", out.A)
+	equal(t, true, out.B)
+	equal(t, "2=8", out.C)
+	equal(t, 3.14, out.D)
+}
+
+func Test_Process(t *testing.T) {
+	os.Clearenv()
+
+	equal(t, nil, SetTo(Process{}, &simple{A: "test"}))
+	equal(t, "test", os.Getenv("ENV_A"))
+
+	out := new(simple)
+	equal(t, nil, GetFrom(Process{}, out))
+	equal(t, "test", out.A)
+
+	os.Clearenv()
+}
+
+func Test_GetContext(t *testing.T) {
+	src := blockingSource{Map: Map{"ENV_A": "test"}}
+
+	out := new(simple)
+	equal(t, nil, GetContext(context.Background(), src, out))
+	equal(t, "test", out.A)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := GetContext(ctx, src, new(simple))
+	equal(t, "env: cannot get data into Go struct field simple.ENV_A of type string: context canceled", err.Error())
+
+	err = GetContext(ctx, src.Map, new(simple))
+	equal(t, "env: cannot get data into Go struct field simple.ENV_A of type string: context canceled", err.Error())
+
+	err = SetContext(ctx, src.Map, &simple{A: "test"})
+	equal(t, "env: cannot set data from Go struct field simple.ENV_A of type string: context canceled", err.Error())
+}
+
+func Test_Marshal(t *testing.T) {
+	in := &struct {
+		A  string   `env:"A"`
+		A0 int      `env:"A0"`
+		B  []string `env:"B,sep=','"`
+		C  *int     `env:"C"`
+	}{A: "x=y", A0: 1, B: []string{"b", "c"}}
+
+	env, err := Marshal(in)
+	equal(t, nil, err)
+	equal(t, []string{"A=x=y", "A0=1", "B=b,c"}, env)
+
+	out := &struct {
+		A  string   `env:"A"`
+		A0 int      `env:"A0"`
+		B  []string `env:"B,sep=','"`
+		C  *int     `env:"C"`
+	}{}
+	equal(t, nil, Unmarshal(env, out))
+	equal(t, in, out)
+
+	equal(t, nil, Unmarshal([]string{"A=first", "A=second"}, out))
+	equal(t, "second", out.A)
+
+	_, err = Marshal(nil)
+	equal(t, true, err != nil)
+}
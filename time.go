@@ -0,0 +1,177 @@
+package envio
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	locationType = reflect.TypeOf((*time.Location)(nil))
+)
+
+// Layouts that can be used by name in the layout tag option.
+var layouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    "2006-01-02 15:04:05",
+	"DateOnly":    "2006-01-02",
+	"TimeOnly":    "15:04:05",
+}
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+func durationProc(s *getterState, str string, v reflect.Value) error {
+	d, err := parseDuration(str, s.durationDays)
+	if err != nil {
+		return err
+	}
+	v.SetInt(int64(d))
+	return nil
+}
+
+// parseDuration is like time.ParseDuration but also accepts the units "d" and "w" if days is set.
+func parseDuration(str string, days bool) (time.Duration, error) {
+	if !days {
+		return time.ParseDuration(str)
+	}
+
+	var (
+		d    time.Duration
+		rest string
+		neg  bool
+	)
+
+	orig := str
+	if str != "" && (str[0] == '-' || str[0] == '+') {
+		neg = str[0] == '-'
+		str = str[1:]
+	}
+
+	for i := 0; i < len(str); {
+		j := i
+		for j < len(str) && (str[j] == '.' || '0' <= str[j] && str[j] <= '9') {
+			j++
+		}
+		k := j
+		for k < len(str) && str[k] != '.' && (str[k] < '0' || '9' < str[k]) {
+			k++
+		}
+
+		var unit time.Duration
+		switch str[j:k] {
+		case "d":
+			unit = day
+		case "w":
+			unit = week
+		default:
+			rest += str[i:k]
+			i = k
+			continue
+		}
+
+		n, err := strconv.ParseFloat(str[i:j], 64)
+		if err != nil || j == i {
+			return 0, fmt.Errorf("time: invalid duration %q", orig)
+		}
+		d += time.Duration(n * float64(unit))
+		i = k
+	}
+
+	if rest != "" {
+		r, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("time: invalid duration %q", orig)
+		}
+		d += r
+	}
+
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+func durationFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	return append(s.scratch[:0], time.Duration(v.Int()).String()...), nil
+}
+
+func timeProc(s *getterState, str string, v reflect.Value) error {
+	var (
+		t   time.Time
+		err error
+	)
+
+	switch s.field.layout {
+	case "unix":
+		var sec int64
+		if sec, err = strconv.ParseInt(str, 10, 64); err == nil {
+			t = time.Unix(sec, 0)
+		}
+	case "unixmilli":
+		var msec int64
+		if msec, err = strconv.ParseInt(str, 10, 64); err == nil {
+			t = time.UnixMilli(msec)
+		}
+	default:
+		t, err = time.Parse(timeLayout(s.field.layout, time.RFC3339), str)
+	}
+
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(t))
+	return nil
+}
+
+func timeFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	t := v.Interface().(time.Time)
+
+	switch s.field.layout {
+	case "unix":
+		return strconv.AppendInt(s.scratch[:0], t.Unix(), 10), nil
+	case "unixmilli":
+		return strconv.AppendInt(s.scratch[:0], t.UnixMilli(), 10), nil
+	default:
+		return t.AppendFormat(s.scratch[:0], timeLayout(s.field.layout, time.RFC3339Nano)), nil
+	}
+}
+
+// timeLayout returns the layout for the value of the layout tag option.
+func timeLayout(layout, def string) string {
+	if layout == "" {
+		return def
+	}
+	if l, ok := layouts[layout]; ok {
+		return l
+	}
+	return layout
+}
+
+func locationProc(_ *getterState, str string, v reflect.Value) error {
+	loc, err := time.LoadLocation(str)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(loc))
+	return nil
+}
+
+func locationFormat(s *setterState, v reflect.Value) ([]byte, error) {
+	return append(s.scratch[:0], v.Interface().(*time.Location).String()...), nil
+}
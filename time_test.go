@@ -0,0 +1,180 @@
+package envio
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+type times struct {
+	Timeout   time.Duration    `env:"TIMEOUT"`
+	Retries   []time.Duration  `env:"RETRIES"`
+	Deadline  *time.Duration   `env:"DEADLINE"`
+	Start     time.Time        `env:"START"`
+	Date      time.Time        `env:"DATE,layout=DateOnly"`
+	Stamp     time.Time        `env:"STAMP,layout=unix"`
+	Custom    [2]time.Time     `env:"CUSTOM,layout=02.01.2006"`
+	Zone      *time.Location   `env:"ZONE"`
+	Zones     []*time.Location `env:"ZONES"`
+	NoZone    *time.Location   `env:"NO_ZONE"`
+	NoTimeout *time.Duration   `env:"NO_TIMEOUT"`
+}
+
+func Test_parseDuration(t *testing.T) {
+	tests := []struct {
+		input  string
+		days   bool
+		expect time.Duration
+		err    error
+	}{
+		{input: "30s", expect: 30 * time.Second},
+		{input: "1h30m", days: true, expect: 90 * time.Minute},
+		{input: "2d", days: true, expect: 48 * time.Hour},
+		{input: "1w2d12h", days: true, expect: 9*24*time.Hour + 12*time.Hour},
+		{input: "-1.5d", days: true, expect: -36 * time.Hour},
+		{input: "0", days: true, expect: 0},
+		{input: "2d", err: errors.New(`time: unknown unit "d" in duration "2d"`)},
+		{input: "d", days: true, err: errors.New(`time: invalid duration "d"`)},
+		{input: "1d1x", days: true, err: errors.New(`time: invalid duration "1d1x"`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			d, err := parseDuration(tt.input, tt.days)
+			if tt.err != nil {
+				equal(t, tt.err.Error(), err.Error())
+				return
+			}
+			equal(t, nil, err)
+			equal(t, tt.expect, d)
+		})
+	}
+}
+
+func Test_Times(t *testing.T) {
+	utc := time.UTC
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database is not available")
+	}
+	deadline := time.Minute
+
+	in := &times{
+		Timeout:  90 * time.Second,
+		Retries:  []time.Duration{time.Second, 500 * time.Millisecond},
+		Deadline: &deadline,
+		Start:    time.Date(2024, 5, 1, 12, 30, 0, 500, time.UTC),
+		Date:     time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Stamp:    time.Unix(1700000000, 0),
+		Custom:   [2]time.Time{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)},
+		Zone:     berlin,
+		Zones:    []*time.Location{utc, berlin},
+	}
+
+	os.Clearenv()
+	equal(t, nil, Set(in))
+
+	for _, v := range []env{
+		{name: "TIMEOUT", value: "1m30s"},
+		{name: "RETRIES", value: "1s" + string(envSeparator) + "500ms"},
+		{name: "DEADLINE", value: "1m0s"},
+		{name: "START", value: "2024-05-01T12:30:00.0000005Z"},
+		{name: "DATE", value: "2024-05-01"},
+		{name: "STAMP", value: "1700000000"},
+		{name: "CUSTOM", value: "01.05.2024" + string(envSeparator) + "31.12.2024"},
+		{name: "ZONE", value: "Europe/Berlin"},
+		{name: "ZONES", value: "UTC" + string(envSeparator) + "Europe/Berlin"},
+	} {
+		equal(t, v.value, os.Getenv(v.name))
+	}
+
+	out := new(times)
+	equal(t, nil, Get(out))
+	equal(t, in.Timeout, out.Timeout)
+	equal(t, in.Retries, out.Retries)
+	equal(t, in.Deadline, out.Deadline)
+	equal(t, true, in.Start.Equal(out.Start))
+	equal(t, true, in.Date.Equal(out.Date))
+	equal(t, true, in.Stamp.Equal(out.Stamp))
+	equal(t, true, in.Custom[1].Equal(out.Custom[1]))
+	equal(t, in.Zone.String(), out.Zone.String())
+	equal(t, 2, len(out.Zones))
+	equal(t, in.Zones[1].String(), out.Zones[1].String())
+	equal(t, (*time.Location)(nil), out.NoZone)
+	equal(t, (*time.Duration)(nil), out.NoTimeout)
+
+	os.Clearenv()
+}
+
+func Test_TimesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  env
+		opts []Option
+		err  error
+	}{
+		{
+			name: "integer duration",
+			env:  env{name: "TIMEOUT", value: "30"},
+			err:  errors.New(`env: cannot get data into Go struct field times.TIMEOUT of type time.Duration: time: missing unit in duration "30"`),
+		},
+		{
+			name: "days without option",
+			env:  env{name: "TIMEOUT", value: "1d"},
+			err:  errors.New(`env: cannot get data into Go struct field times.TIMEOUT of type time.Duration: time: unknown unit "d" in duration "1d"`),
+		},
+		{
+			name: "days with option",
+			env:  env{name: "RETRIES", value: "1d"},
+			opts: []Option{ExtendedDurations()},
+		},
+		{
+			name: "invalid location",
+			env:  env{name: "ZONE", value: "Nowhere/City"},
+			err:  errors.New(`env: cannot get data into Go struct field times.ZONE of type *time.Location: unknown time zone Nowhere/City`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			equal(t, nil, os.Setenv(tt.env.name, tt.env.value))
+
+			err := Get(new(times), tt.opts...)
+			if tt.err != nil {
+				equal(t, tt.err.Error(), err.Error())
+				return
+			}
+			equal(t, nil, err)
+		})
+	}
+
+	os.Clearenv()
+}
+
+func Test_TimeList(t *testing.T) {
+	type list struct {
+		Times []time.Time `env:"TIMES"`
+	}
+
+	in := &list{Times: []time.Time{
+		time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC),
+	}}
+
+	// The default layout RFC 3339 contains ':', the default separator on Unix.
+	for _, opts := range [][]Option{nil, {Separator(",")}, {Escape(EscapeBackslash)}, {Escape(EscapeQuote)}} {
+		src := Map{}
+		err := SetTo(src, in, opts...)
+		if opts == nil && envSeparator == ':' {
+			equal(t, `env: cannot set data from Go struct field list.TIMES of type []time.Time: element "2024-01-01T10:00:00Z" contains the separator ":", set another separator or use the Escape option`, err.Error())
+			continue
+		}
+		equal(t, nil, err)
+
+		out := new(list)
+		equal(t, nil, GetFrom(src, out, opts...))
+		equal(t, in, out)
+	}
+}
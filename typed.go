@@ -0,0 +1,54 @@
+package envio
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// Load gets values from environment variables to a new value of the type T.
+func Load[T any](opts ...Option) (T, error) {
+	var v T
+	err := Get(&v, opts...)
+	return v, err
+}
+
+// MustLoad is like Load but panics if the values cannot be got, for use in main.
+func MustLoad[T any](opts ...Option) T {
+	v, err := Load[T](opts...)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Lookup gets the value of the variable named by the key as the type T, as if it were a struct field without options,
+// and reports whether the variable is present.
+func Lookup[T any](key string, opts ...Option) (T, bool, error) {
+	var v T
+
+	s := newEngine(opts).newGetState(context.Background())
+	defer getStatePool.Put(s)
+
+	s.field.name, s.field.hasName = key, true
+	if err := s.reflectValue(reflect.ValueOf(&v).Elem()); err != nil {
+		if !errors.Is(err, errExist) {
+			s.setError(name, getError, err)
+		}
+		return v, false, s.err
+	}
+	if len(s.errs) != 0 {
+		return v, false, s.errs
+	}
+	return v, s.present, nil
+}
+
+// GetOr returns the value of the variable named by the key as the type T,
+// or def if the variable is missing or its value cannot be parsed.
+func GetOr[T any](key string, def T, opts ...Option) T {
+	v, ok, err := Lookup[T](key, opts...)
+	if !ok || err != nil {
+		return def
+	}
+	return v
+}
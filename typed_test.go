@@ -0,0 +1,78 @@
+package envio
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_Load(t *testing.T) {
+	t.Setenv("HOST", "localhost")
+	t.Setenv("PORT", "8080")
+
+	type config struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	c, err := Load[config]()
+	equal(t, nil, err)
+	equal(t, config{Host: "localhost", Port: 8080}, c)
+
+	p, err := Load[*config]()
+	equal(t, nil, err)
+	equal(t, &config{Host: "localhost", Port: 8080}, p)
+
+	equal(t, c, MustLoad[config]())
+
+	t.Setenv("PORT", "http")
+	_, err = Load[config]()
+	var fe *FieldError
+	equal(t, true, errors.As(err, &fe))
+
+	defer func() {
+		equal(t, true, recover() != nil)
+	}()
+	MustLoad[config]()
+}
+
+func Test_Lookup(t *testing.T) {
+	t.Setenv("TIMEOUT", "1m30s")
+	t.Setenv("PORTS", "80"+string(envSeparator)+"443")
+	t.Setenv("EMPTY", "")
+	t.Setenv("BAD", "x")
+
+	d, ok, err := Lookup[time.Duration]("TIMEOUT")
+	equal(t, nil, err)
+	equal(t, true, ok)
+	equal(t, 90*time.Second, d)
+
+	ports, ok, err := Lookup[[]int]("PORTS")
+	equal(t, nil, err)
+	equal(t, true, ok)
+	equal(t, []int{80, 443}, ports)
+
+	n, ok, err := Lookup[*int]("EMPTY")
+	equal(t, nil, err)
+	equal(t, true, ok)
+	equal(t, 0, *n)
+
+	n, ok, err = Lookup[*int]("MISSING")
+	equal(t, nil, err)
+	equal(t, false, ok)
+	equal(t, (*int)(nil), n)
+
+	_, ok, err = Lookup[int]("BAD")
+	equal(t, false, ok)
+	equal(t, `env: cannot get data into $BAD of type int: invalid syntax`, err.Error())
+}
+
+func Test_GetOr(t *testing.T) {
+	t.Setenv("WORKERS", "8")
+	t.Setenv("BAD", "x")
+
+	equal(t, 8, GetOr("WORKERS", 4))
+	equal(t, 4, GetOr("MISSING", 4))
+	equal(t, 4, GetOr("BAD", 4))
+	equal(t, "a", GetOr("MISSING", "a"))
+}
@@ -0,0 +1,125 @@
+package envio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+)
+
+// Unsetter is the interface implemented by sinks that variables can be removed from.
+type Unsetter interface {
+	Sink
+	// Unset removes the variable named by the key.
+	Unset(key string) error
+}
+
+// Unset removes the environment variables that v is set to, as named by Set.
+// If v is nil, Unset returns a setter error.
+func Unset(v any, opts ...Option) error {
+	return newEngine(opts).unset(context.Background(), v)
+}
+
+// UnsetFrom removes the variables that v is set to from the sink.
+// With the OnlyUnchanged option, the sink must also be a Source.
+func UnsetFrom(sink Unsetter, v any, opts ...Option) error {
+	return UnsetContext(context.Background(), sink, v, opts...)
+}
+
+// UnsetContext is like UnsetFrom but stops when the context is done.
+func UnsetContext(ctx context.Context, sink Unsetter, v any, opts ...Option) error {
+	c := *newEngine(opts)
+	c.sink = sink
+	c.source, _ = sink.(Source)
+	return c.unset(ctx, v)
+}
+
+// KeepMandatory makes Unset keep the variables of mandatory fields.
+func KeepMandatory() Option {
+	return func(e *engine) {
+		e.keepMandatory = true
+	}
+}
+
+// OnlyUnchanged makes Unset remove only the variables that still hold the values Set would write for v.
+// Variables that Set does not write, such as those of nil pointers, are kept.
+func OnlyUnchanged() Option {
+	return func(e *engine) {
+		e.onlyUnchanged = true
+	}
+}
+
+func (e *engine) unset(ctx context.Context, v any) error {
+	s := e.newSetState(ctx)
+	defer setStatePool.Put(s)
+
+	s.unsetting = true
+	s.set(v)
+	return s.err
+}
+
+// unsetEnv removes the variable of the current field, v is the value Set would write if written is true.
+func (s *setterState) unsetEnv(v []byte, written bool) error {
+	return s.unsetVar(s.varName(), v, written)
+}
+
+// unsetVar removes the variable named by the key, v is the value Set would write if written is true.
+func (s *setterState) unsetVar(key string, v []byte, written bool) error {
+	if s.onlyUnchanged {
+		if !written {
+			return nil
+		}
+		if s.source == nil {
+			return errors.New("the sink is not a source")
+		}
+		str, ok, err := lookup(s.ctx, s.source, key)
+		if err != nil {
+			return err
+		}
+		if !ok || str != string(v) {
+			return nil
+		}
+	}
+	return s.removeVar(key)
+}
+
+// removeVar removes the variable named by the key from the sink.
+// When patching, its name is added to the written variables.
+func (s *setterState) removeVar(key string) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	u, ok := s.sink.(Unsetter)
+	if !ok {
+		return errors.New("the sink cannot unset variables")
+	}
+	err := u.Unset(key)
+	if err == nil && s.patching {
+		s.written = append(s.written, key)
+	}
+	return err
+}
+
+// Unset removes the environment variable named by the key.
+func (Process) Unset(key string) error {
+	return os.Unsetenv(key)
+}
+
+// Unset removes the key from the map.
+func (m Map) Unset(key string) error {
+	delete(m, key)
+	return nil
+}
+
+// Unset removes all entries with the key.
+func (e *Environ) Unset(key string) error {
+	entries := (*e)[:0]
+	for _, entry := range *e {
+		if k, _, ok := strings.Cut(entry, "="); !ok || k != key {
+			entries = append(entries, entry)
+		}
+	}
+	*e = entries
+	return nil
+}
@@ -0,0 +1,56 @@
+package envio
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+type unsetted struct {
+	nested `env:",prefix=EMB_"`
+	DB     *simple `env:",prefix=DB_"`
+	Custom ai      `env:"CUSTOM"`
+	Hosts  []string
+	Port   *int `env:"PORT"`
+}
+
+func Test_Unset(t *testing.T) {
+	in := &unsetted{
+		nested: nested{X: "x", simple: simple{A: "a"}},
+		DB:     &simple{A: "db", C: 1},
+		Custom: 1,
+		Hosts:  []string{"a", "b"},
+	}
+
+	src := Map{"OTHER": "other"}
+	equal(t, nil, SetTo(src, in))
+	src["PORT"] = "80"
+	equal(t, nil, UnsetFrom(src, in))
+	equal(t, Map{"OTHER": "other"}, src)
+
+	equal(t, nil, SetTo(src, in))
+	equal(t, nil, UnsetFrom(src, in, KeepMandatory()))
+	equal(t, Map{"OTHER": "other", "EMB_ENV_A": "a", "DB_ENV_A": "db"}, src)
+
+	equal(t, nil, SetTo(src, in))
+	src["EMB_ENV_X"] = "changed"
+	src["PORT"] = "80"
+	equal(t, nil, UnsetFrom(src, in, OnlyUnchanged()))
+	equal(t, Map{"OTHER": "other", "EMB_ENV_X": "changed", "PORT": "80"}, src)
+
+	env := Environ{"ENV_A=first", "ENV_B=true", "ENV_A=second", "OTHER=other"}
+	equal(t, nil, UnsetFrom(&env, &simple{}))
+	equal(t, Environ{"OTHER=other"}, env)
+
+	os.Clearenv()
+	equal(t, nil, Set(&simple{A: "a", B: true}))
+	equal(t, nil, os.Setenv("OTHER", "other"))
+	equal(t, nil, Unset(simple{}))
+	equal(t, []string{"OTHER=other"}, os.Environ())
+
+	err := UnsetFrom(src, &struct {
+		A string `env:"ENV_A,m,default=a"`
+	}{})
+	equal(t, true, errors.Is(err, ErrTagConflict))
+	equal(t, true, Unset(nil) != nil)
+}
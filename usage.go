@@ -0,0 +1,304 @@
+package envio
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// descTag is the name of the struct tag holding the description of a variable,
+// for descriptions too long for the desc tag option.
+const descTag = "envdesc"
+
+// UsageFormat is the format of the documentation rendered by Usage.
+type UsageFormat int
+
+const (
+	// UsageText renders the variables like flag.PrintDefaults, for -help.
+	UsageText UsageFormat = iota
+	// UsageMarkdown renders the variables as a Markdown table.
+	UsageMarkdown
+	// UsageDotenv renders the variables as a .env.example template.
+	UsageDotenv
+)
+
+// Variable describes a variable that a struct field is got from and set to.
+type Variable struct {
+	Name              string       // name of the variable, with the prefixes
+	Field             string       // path of the struct field, such as DB.Host
+	Type              reflect.Type // type of the field
+	Mandatory         bool         // the variable must be set
+	NotEmpty          bool         // the variable must not be empty if it is set
+	Default           string       // value used when the variable is missing
+	HasDefault        bool         // the field has a default value
+	File              bool         // the value is the path to a file holding the value
+	Separator         string       // separator between the elements of a list or the pairs of a map
+	KeyValueSeparator string       // separator between the key and the value of a map pair
+	Description       string       // description from the desc tag option or the envdesc tag
+}
+
+// Describe returns the variables of the struct type of v, which may be a nil pointer, in the order of the fields.
+// Names are resolved as Get and Set resolve them, so they include the prefixes,
+// and the index in the names of the variables of lists of structs is "<n>", the key of maps of structs is "<key>".
+func Describe(v any, opts ...Option) ([]Variable, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: the input value is not a struct", name)
+	}
+
+	e := newEngine(opts)
+	var vars []Variable
+	if err := e.describeStruct(&vars, t, nil, "", ""); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// describeStruct appends the variables of the struct type t to vars. parents are the struct types on the path to t:
+// the variables of a type that contains itself through nested structs or lists of structs cannot be listed.
+func (e *engine) describeStruct(vars *[]Variable, t reflect.Type, parents []reflect.Type, prefix, path string) error {
+	for _, p := range parents {
+		if p == t {
+			return fmt.Errorf("%w: recursive type %s", ErrNotSupportType, t)
+		}
+	}
+	return e.describe(vars, e.cachedFields(t), append(parents, t), prefix, path)
+}
+
+func (e *engine) describe(vars *[]Variable, fs structFields, parents []reflect.Type, prefix, path string) error {
+	for _, f := range fs {
+		if f.err != nil {
+			return f.err
+		}
+
+		p := prefix + e.fieldPrefix(f)
+
+		if f.embedded != nil {
+			if err := e.describe(vars, f.embedded, parents, p, path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if f.nested {
+			t := f.typ
+			if t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if err := e.describeStruct(vars, t, parents, p, path+f.goName+"."); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if f.indexed {
+			t := f.typ
+			if t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			index := "n"
+			if t.Kind() == reflect.Map {
+				index = "key"
+			}
+			if t = t.Elem(); t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if err := e.describeStruct(vars, t, parents, e.listPrefix(p, f)+"<"+index+">"+e.indexDelimiter(), path+f.goName+"["+index+"]."); err != nil {
+				return err
+			}
+			continue
+		}
+
+		v := Variable{
+			Name:        prefix + f.envName(e.naming),
+			Field:       path + f.goName,
+			Type:        f.typ,
+			Mandatory:   f.mandatory,
+			NotEmpty:    f.notEmpty,
+			Default:     f.def,
+			HasDefault:  f.hasDef,
+			File:        f.file,
+			Description: f.desc,
+		}
+
+		switch t := f.typ; listKind(t) {
+		case reflect.Slice:
+			if !f.raw || t.Elem().Kind() != reflect.Uint8 {
+				v.Separator = string(e.fieldSeparator(f))
+			}
+		case reflect.Map:
+			pair, kv := e.fieldMapSeparators(f)
+			v.Separator, v.KeyValueSeparator = string(pair), string(kv)
+		}
+
+		*vars = append(*vars, v)
+	}
+	return nil
+}
+
+// listKind returns reflect.Slice for arrays and slices and reflect.Map for maps
+// that are got/set element by element, otherwise reflect.Invalid.
+func listKind(t reflect.Type) reflect.Kind {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if _, ok := typeProcs(t); ok {
+		return reflect.Invalid
+	}
+	if p := reflect.PointerTo(t); p.Implements(getter) || p.Implements(textUnmarshaler) {
+		return reflect.Invalid
+	}
+
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice:
+		return reflect.Slice
+	case reflect.Map:
+		return reflect.Map
+	default:
+		return reflect.Invalid
+	}
+}
+
+// Usage renders the documentation of the variables of the struct type of v in the format.
+func Usage(v any, format UsageFormat, opts ...Option) (string, error) {
+	vars, err := Describe(v, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	switch format {
+	case UsageText:
+		usageText(&b, vars)
+	case UsageMarkdown:
+		usageMarkdown(&b, vars)
+	case UsageDotenv:
+		usageDotenv(&b, vars)
+	default:
+		return "", fmt.Errorf("%s: unknown usage format %d", name, format)
+	}
+	return b.String(), nil
+}
+
+// notes returns the properties of the variable other than the name, type, default and description.
+func (v *Variable) notes() []string {
+	var notes []string
+	if v.Mandatory {
+		notes = append(notes, "required")
+	}
+	if v.NotEmpty {
+		notes = append(notes, "not empty")
+	}
+	if v.File {
+		notes = append(notes, "path to a file")
+	}
+	if v.Separator != "" {
+		notes = append(notes, fmt.Sprintf("separated by %q", v.Separator))
+	}
+	if v.KeyValueSeparator != "" {
+		notes = append(notes, fmt.Sprintf("key and value separated by %q", v.KeyValueSeparator))
+	}
+	return notes
+}
+
+func usageText(b *strings.Builder, vars []Variable) {
+	for _, v := range vars {
+		fmt.Fprintf(b, "  %s %s", v.Name, v.Type)
+		if notes := v.notes(); len(notes) != 0 {
+			fmt.Fprintf(b, " (%s)", strings.Join(notes, ", "))
+		}
+		b.WriteByte('\n')
+
+		usage := v.Description
+		if v.HasDefault {
+			if usage != "" {
+				usage += " "
+			}
+			usage += fmt.Sprintf("(default %q)", v.Default)
+		}
+		if usage != "" {
+			b.WriteString("    \t")
+			b.WriteString(strings.ReplaceAll(usage, "\n", "\n    \t"))
+			b.WriteByte('\n')
+		}
+	}
+}
+
+func usageMarkdown(b *strings.Builder, vars []Variable) {
+	b.WriteString("| Variable | Type | Required | Default | Description |\n")
+	b.WriteString("|----------|------|----------|---------|-------------|\n")
+	for _, v := range vars {
+		required, def := "no", ""
+		if v.Mandatory {
+			required = "yes"
+		}
+		if v.HasDefault {
+			def = markdownCode(v.Default)
+		}
+
+		desc := v.Description
+		var notes []string
+		for _, n := range v.notes() {
+			if n != "required" {
+				notes = append(notes, n)
+			}
+		}
+		if len(notes) != 0 {
+			if desc != "" {
+				desc += " "
+			}
+			desc += "(" + strings.Join(notes, ", ") + ")"
+		}
+
+		fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n",
+			markdownCode(v.Name), markdownCode(v.Type.String()), required, def, markdownCell(desc))
+	}
+}
+
+// markdownCode returns the string as inline code that fits in a table cell.
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + markdownCell(s) + fence
+}
+
+// markdownCell escapes the characters that would break a table row.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(s)
+}
+
+func usageDotenv(b *strings.Builder, vars []Variable) {
+	for i, v := range vars {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		for _, line := range strings.Split(v.Description, "\n") {
+			if line != "" {
+				fmt.Fprintf(b, "# %s\n", line)
+			}
+		}
+
+		fmt.Fprintf(b, "# %s", v.Type)
+		if notes := v.notes(); len(notes) != 0 {
+			fmt.Fprintf(b, ", %s", strings.Join(notes, ", "))
+		}
+		b.WriteByte('\n')
+
+		// Optional variables are commented out, so that the template does not override the defaults.
+		if !v.Mandatory {
+			b.WriteString("# ")
+		}
+		fmt.Fprintf(b, "%s=%s\n", v.Name, quoteDotenv(v.Default))
+	}
+}
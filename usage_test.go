@@ -0,0 +1,140 @@
+package envio
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type documented struct {
+	Port    int               `env:"PORT,default=8080,desc='Port to listen on, 1-65535.'"`
+	Hosts   []string          `env:"HOSTS,m" envdesc:"Hosts | peers."`
+	Labels  map[string]string `env:"LABELS,sep=;"`
+	Timeout time.Duration     `env:"TIMEOUT,default=5s"`
+	Token   string            `env:"TOKEN,file,notempty"`
+	DB      *struct {
+		Host string `env:"HOST,m" envdesc:"Database host."`
+	} `env:",prefix=DB_"`
+	Cache struct {
+		TTL time.Duration `env:"TTL"`
+	}
+	simple `env:",prefix=EMB_"`
+}
+
+func Test_Describe(t *testing.T) {
+	vars, err := Describe((*documented)(nil), AutoPrefix("_"))
+	equal(t, nil, err)
+
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		names[i] = v.Name
+	}
+	equal(t, []string{
+		"PORT", "HOSTS", "LABELS", "TIMEOUT", "TOKEN", "DB_HOST", "Cache_TTL",
+		"EMB_ENV_A", "EMB_ENV_B", "EMB_ENV_C", "EMB_D",
+	}, names)
+
+	equal(t, Variable{
+		Name:        "PORT",
+		Field:       "Port",
+		Type:        reflect.TypeOf(0),
+		Default:     "8080",
+		HasDefault:  true,
+		Description: "Port to listen on, 1-65535.",
+	}, vars[0])
+	equal(t, string(envSeparator), vars[1].Separator)
+	equal(t, [2]string{";", "="}, [2]string{vars[2].Separator, vars[2].KeyValueSeparator})
+	equal(t, "", vars[3].Separator)
+	equal(t, "DB.Host", vars[5].Field)
+	equal(t, "Database host.", vars[5].Description)
+
+	_, err = Describe(0)
+	equal(t, true, err != nil)
+
+	_, err = Describe(conflict{})
+	equal(t, true, errors.Is(err, ErrTagConflict))
+}
+
+func Test_Usage(t *testing.T) {
+	type config struct {
+		Port  int      `env:"PORT,default=8080,desc='Port to listen on.'"`
+		Hosts []string `env:"HOSTS,m,sep=','" envdesc:"Hosts | peers."`
+		Token string   `env:"TOKEN,file"`
+		DB    struct {
+			Host string `env:"HOST,default=localhost"`
+		} `env:",prefix=DB_"`
+	}
+
+	tests := []struct {
+		format UsageFormat
+		exp    string
+	}{
+		{
+			format: UsageText,
+			exp: `  PORT int
+    	Port to listen on. (default "8080")
+  HOSTS []string (required, separated by ",")
+    	Hosts | peers.
+  TOKEN string (path to a file)
+  DB_HOST string
+    	(default "localhost")
+`,
+		},
+		{
+			format: UsageMarkdown,
+			exp: "| Variable | Type | Required | Default | Description |\n" +
+				"|----------|------|----------|---------|-------------|\n" +
+				"| `PORT` | `int` | no | `8080` | Port to listen on. |\n" +
+				"| `HOSTS` | `[]string` | yes |  | Hosts \\| peers. (separated by \",\") |\n" +
+				"| `TOKEN` | `string` | no |  | (path to a file) |\n" +
+				"| `DB_HOST` | `string` | no | `localhost` |  |\n",
+		},
+		{
+			format: UsageDotenv,
+			exp: `# Port to listen on.
+# int
+# PORT=8080
+
+# Hosts | peers.
+# []string, required, separated by ","
+HOSTS=
+
+# string, path to a file
+# TOKEN=
+
+# string
+# DB_HOST=localhost
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got, err := Usage(&config{}, test.format)
+		equal(t, nil, err)
+		equal(t, test.exp, got)
+	}
+
+	_, err := Usage(config{}, UsageFormat(-1))
+	equal(t, true, err != nil)
+}
+
+func Test_DescribeDeclaredTypes(t *testing.T) {
+	type declared struct {
+		D *string `env:"D"`
+		I any     `env:"I"`
+	}
+
+	types := func() []string {
+		vars, err := Describe(&declared{})
+		equal(t, nil, err)
+		return []string{vars[0].Type.String(), vars[1].Type.String()}
+	}
+
+	exp := []string{"*string", "interface {}"}
+	equal(t, exp, types())
+
+	equal(t, nil, GetFrom(Map{"D": "x", "I": "1"}, &declared{I: new(int)}))
+	equal(t, nil, SetTo(Map{}, &declared{I: 1}))
+	equal(t, exp, types())
+}
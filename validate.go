@@ -0,0 +1,248 @@
+package envio
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// RuleError describes a value that breaks a validation rule of a field.
+type RuleError struct {
+	Field string // name of the struct field
+	Rule  string // the rule, such as "min=1"
+	Msg   string // why the value breaks the rule
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("field %s breaks the rule %s: %s", e.Field, e.Rule, e.Msg)
+}
+
+// rule checks a value after it is got. The check returns the reason why the value breaks the rule, if it does.
+type rule struct {
+	name  string
+	check func(v reflect.Value) string
+}
+
+// newRule returns the rule for the tag option, t is the type of the field.
+// Rules apply to the values that pointers point to.
+func newRule(t reflect.Type, opt, arg string) (rule, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	r := rule{name: opt}
+	if arg != "" {
+		r.name += "=" + arg
+	}
+
+	var err error
+	switch opt {
+	case "min", "max":
+		r.check, err = boundRule(t, arg, opt == "min")
+	case "oneof":
+		r.check, err = oneOfRule(t, arg)
+	case "regex":
+		r.check, err = regexRule(t, arg)
+	case "nonzero":
+		r.check = func(v reflect.Value) string {
+			if v.IsZero() || (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+				return "the value is zero"
+			}
+			return ""
+		}
+	case "url":
+		r.check, err = stringRule(t, func(str string) string {
+			if u, err := url.Parse(str); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Sprintf("%q is not an absolute URL", str)
+			}
+			return ""
+		})
+	case "scheme":
+		r.check, err = schemeRule(t, arg)
+	case "hostport":
+		r.check, err = stringRule(t, func(str string) string {
+			_, port, err := net.SplitHostPort(str)
+			if err == nil {
+				_, err = strconv.ParseUint(port, 10, 16)
+			}
+			if err != nil {
+				return fmt.Sprintf("%q is not a host and a port", str)
+			}
+			return ""
+		})
+	}
+	return r, err
+}
+
+// boundRule checks the minimum or the maximum of numbers and of the lengths of strings, lists and maps.
+// Bounds of durations are durations.
+func boundRule(t reflect.Type, arg string, min bool) (func(reflect.Value) string, error) {
+	var (
+		// compare compares the value with the bound and describes the value.
+		compare func(v reflect.Value) (int, string)
+		err     error
+	)
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var bound int64
+		if t == durationType {
+			var d time.Duration
+			d, err = parseDuration(arg, true)
+			bound = int64(d)
+		} else {
+			bound, err = strconv.ParseInt(arg, 10, 64)
+		}
+		compare = func(v reflect.Value) (int, string) {
+			return cmp.Compare(v.Int(), bound), fmt.Sprint(v.Interface())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var bound uint64
+		bound, err = strconv.ParseUint(arg, 10, 64)
+		compare = func(v reflect.Value) (int, string) {
+			return cmp.Compare(v.Uint(), bound), fmt.Sprint(v.Interface())
+		}
+	case reflect.Float32, reflect.Float64:
+		var bound float64
+		bound, err = strconv.ParseFloat(arg, 64)
+		compare = func(v reflect.Value) (int, string) {
+			return cmp.Compare(v.Float(), bound), fmt.Sprint(v.Interface())
+		}
+	case reflect.String, reflect.Array, reflect.Slice, reflect.Map:
+		var bound int
+		bound, err = strconv.Atoi(arg)
+		compare = func(v reflect.Value) (int, string) {
+			n := v.Len()
+			if v.Kind() == reflect.String {
+				n = utf8.RuneCountInString(v.String())
+			}
+			return cmp.Compare(n, bound), fmt.Sprintf("the length %d", n)
+		}
+	default:
+		return nil, notApplicable(t)
+	}
+
+	if err != nil {
+		return nil, unwrapErr(err)
+	}
+
+	return func(v reflect.Value) string {
+		switch c, str := compare(v); {
+		case min && c < 0:
+			return fmt.Sprintf("%s is less than %s", str, arg)
+		case !min && c > 0:
+			return fmt.Sprintf("%s is greater than %s", str, arg)
+		default:
+			return ""
+		}
+	}, nil
+}
+
+// oneOfRule checks that the value, formatted as by fmt.Sprint, is one of the values separated by '|'.
+func oneOfRule(t reflect.Type, arg string) (func(reflect.Value) string, error) {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+	default:
+		return nil, notApplicable(t)
+	}
+
+	values := strings.Split(arg, "|")
+	return func(v reflect.Value) string {
+		str := fmt.Sprint(v.Interface())
+		for _, value := range values {
+			if str == value {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%q is not one of %s", str, arg)
+	}, nil
+}
+
+// regexRule checks that strings match the regular expression, which is not anchored.
+func regexRule(t reflect.Type, arg string) (func(reflect.Value) string, error) {
+	re, err := regexp.Compile(arg)
+	if err != nil {
+		return nil, err
+	}
+	return stringRule(t, func(str string) string {
+		if !re.MatchString(str) {
+			return fmt.Sprintf("%q does not match %s", str, arg)
+		}
+		return ""
+	})
+}
+
+// schemeRule checks that the scheme of URLs, or of strings parsed as URLs, is one of the schemes separated by '|'.
+func schemeRule(t reflect.Type, arg string) (func(reflect.Value) string, error) {
+	if t != urlType && t.Kind() != reflect.String {
+		return nil, notApplicable(t)
+	}
+
+	schemes := strings.Split(arg, "|")
+	return func(v reflect.Value) string {
+		var scheme string
+		if v.Kind() == reflect.String {
+			u, err := url.Parse(v.String())
+			if err != nil {
+				return fmt.Sprintf("%q is not a URL", v.String())
+			}
+			scheme = u.Scheme
+		} else {
+			scheme = v.Interface().(url.URL).Scheme
+		}
+
+		for _, s := range schemes {
+			if strings.EqualFold(scheme, s) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("the scheme %q is not one of %s", scheme, arg)
+	}, nil
+}
+
+// stringRule returns a check of the values of string types.
+func stringRule(t reflect.Type, check func(string) string) (func(reflect.Value) string, error) {
+	if t.Kind() != reflect.String {
+		return nil, notApplicable(t)
+	}
+	return func(v reflect.Value) string {
+		return check(v.String())
+	}, nil
+}
+
+func invalidRule(t reflect.Type, sf reflect.StructField, opt string, err error) error {
+	return fmt.Errorf("%s: invalid rule %q in Go struct field %s.%s: %w", name, opt, t.Name(), sf.Name, err)
+}
+
+func notApplicable(t reflect.Type) error {
+	return fmt.Errorf("the rule does not apply to type %s", t)
+}
+
+// validate checks the value of the current field against its rules.
+// A missing variable without a default and a nil pointer only break the nonzero rule,
+// so that optional fields are not made mandatory by their rules.
+func (s *getterState) validate(v reflect.Value) error {
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	for _, r := range s.field.rules {
+		if (!s.present || v.Kind() == reflect.Pointer) && r.name != "nonzero" {
+			continue
+		}
+		if msg := r.check(v); msg != "" {
+			return &RuleError{Field: s.field.goName, Rule: r.name, Msg: msg}
+		}
+	}
+	return nil
+}
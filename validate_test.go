@@ -0,0 +1,162 @@
+package envio
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type validated struct {
+	Port    int            `env:"PORT,min=1,max=65535"`
+	Ratio   float64        `env:"RATIO,min=0,max=1"`
+	Workers *uint          `env:"WORKERS,max=8"`
+	Timeout time.Duration  `env:"TIMEOUT,min=1s,max=1m"`
+	Level   string         `env:"LEVEL,oneof=debug|info|warn,default=info"`
+	Name    string         `env:"NAME,min=2,max=4"`
+	Hosts   []string       `env:"HOSTS,min=1"`
+	ID      string         `env:"ID,regex='^[a-z]{2,3}$'"`
+	Token   *string        `env:"TOKEN,nonzero"`
+	Labels  map[string]int `env:"LABELS,nonzero"`
+	URL     string         `env:"URL,url"`
+	Addr    string         `env:"ADDR,hostport"`
+}
+
+func Test_Validate(t *testing.T) {
+	valid := Map{
+		"PORT":    "8080",
+		"RATIO":   "0.5",
+		"TIMEOUT": "30s",
+		"NAME":    "äbc",
+		"HOSTS":   "a",
+		"ID":      "ab",
+		"TOKEN":   "secret",
+		"LABELS":  "a=1",
+		"URL":     "https://example.com/path",
+		"ADDR":    ":8080",
+	}
+
+	out := new(validated)
+	equal(t, nil, GetFrom(valid, out))
+	equal(t, "info", out.Level)
+	equal(t, (*uint)(nil), out.Workers)
+
+	tests := []struct {
+		name  string
+		value string
+		field string
+		rule  string
+		msg   string
+	}{
+		{name: "PORT", value: "0", field: "Port", rule: "min=1", msg: "0 is less than 1"},
+		{name: "PORT", value: "65536", field: "Port", rule: "max=65535", msg: "65536 is greater than 65535"},
+		{name: "RATIO", value: "1.5", field: "Ratio", rule: "max=1", msg: "1.5 is greater than 1"},
+		{name: "WORKERS", value: "9", field: "Workers", rule: "max=8", msg: "9 is greater than 8"},
+		{name: "TIMEOUT", value: "500ms", field: "Timeout", rule: "min=1s", msg: "500ms is less than 1s"},
+		{name: "LEVEL", value: "trace", field: "Level", rule: "oneof=debug|info|warn", msg: `"trace" is not one of debug|info|warn`},
+		{name: "NAME", value: "äbcde", field: "Name", rule: "max=4", msg: "the length 5 is greater than 4"},
+		{name: "HOSTS", value: "", field: "Hosts", rule: "min=1", msg: "the length 0 is less than 1"},
+		{name: "ID", value: "abcd", field: "ID", rule: "regex=^[a-z]{2,3}$", msg: `"abcd" does not match ^[a-z]{2,3}$`},
+		{name: "TOKEN", value: "", field: "Token", rule: "nonzero", msg: "the value is zero"},
+		{name: "LABELS", value: "", field: "Labels", rule: "nonzero", msg: "the value is zero"},
+		{name: "URL", value: "example.com", field: "URL", rule: "url", msg: `"example.com" is not an absolute URL`},
+		{name: "ADDR", value: "localhost:http", field: "Addr", rule: "hostport", msg: `"localhost:http" is not a host and a port`},
+	}
+
+	for _, test := range tests {
+		src := Map{}
+		for k, v := range valid {
+			src[k] = v
+		}
+		src[test.name] = test.value
+
+		err := GetFrom(src, new(validated))
+
+		var fe *FieldError
+		equal(t, true, errors.As(err, &fe))
+		equal(t, test.name, fe.Name)
+
+		var re *RuleError
+		equal(t, true, errors.As(err, &re))
+		equal(t, RuleError{Field: test.field, Rule: test.rule, Msg: test.msg}, *re)
+	}
+
+	delete(valid, "TOKEN")
+	err := GetFrom(valid, new(validated))
+	equal(t, "env: cannot get data into Go struct field validated.TOKEN of type string: field Token breaks the rule nonzero: the value is zero", err.Error())
+
+	src := Map{"PORT": "0", "RATIO": "2"}
+	err = GetFrom(src, new(struct {
+		Port  int     `env:"PORT,min=1"`
+		Ratio float64 `env:"RATIO,max=1"`
+	}), AllErrors())
+	var errs Errors
+	equal(t, true, errors.As(err, &errs))
+	equal(t, 2, len(errs))
+}
+
+func Test_ValidateInvalidRules(t *testing.T) {
+	tests := []struct {
+		v   any
+		err string
+	}{
+		{
+			v: &struct {
+				A bool `env:"A,min=1"`
+			}{},
+			err: `env: invalid rule "min=1" in Go struct field .A: the rule does not apply to type bool`,
+		},
+		{
+			v: &struct {
+				A int `env:"A,max=x"`
+			}{},
+			err: `env: invalid rule "max=x" in Go struct field .A: invalid syntax`,
+		},
+		{
+			v: &struct {
+				A string `env:"A,regex=("`
+			}{},
+			err: "env: invalid rule \"regex=(\" in Go struct field .A: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			v: &struct {
+				A []int `env:"A,url"`
+			}{},
+			err: `env: invalid rule "url" in Go struct field .A: the rule does not apply to type []int`,
+		},
+	}
+
+	for _, test := range tests {
+		err := GetFrom(Map{}, test.v)
+		equal(t, test.err, err.Error())
+	}
+}
+
+func Test_ValidateOptional(t *testing.T) {
+	type optional struct {
+		Port   int     `env:"PORT,min=1,max=65535"`
+		ID     string  `env:"ID,regex='^[a-z]{2,3}$'"`
+		Hosts  []int   `env:"HOSTS,min=1"`
+		Token  *string `env:"TOKEN,nonzero"`
+		Weight int     `env:"WEIGHT,min=1,default=0"`
+	}
+
+	// Missing variables without defaults only break nonzero.
+	err := GetFrom(Map{"WEIGHT": "1"}, new(optional))
+	var re *RuleError
+	equal(t, true, errors.As(err, &re))
+	equal(t, "nonzero", re.Rule)
+
+	out := new(optional)
+	equal(t, nil, GetFrom(Map{"TOKEN": "x", "WEIGHT": "1"}, out))
+	equal(t, 0, out.Port)
+	equal(t, "", out.ID)
+
+	// Present variables, even empty ones, and defaults are validated.
+	err = GetFrom(Map{"TOKEN": "x", "WEIGHT": "1", "ID": ""}, new(optional))
+	equal(t, true, errors.As(err, &re))
+	equal(t, RuleError{Field: "ID", Rule: "regex=^[a-z]{2,3}$", Msg: `"" does not match ^[a-z]{2,3}$`}, *re)
+
+	err = GetFrom(Map{"TOKEN": "x"}, new(optional))
+	equal(t, true, errors.As(err, &re))
+	equal(t, RuleError{Field: "Weight", Rule: "min=1", Msg: "0 is less than 1"}, *re)
+}